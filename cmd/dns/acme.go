@@ -0,0 +1,203 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"os"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var acmeTTL uint32
+var acmeNoFollow bool
+
+// challengeArgs returns the challenge record name and value. They are taken from the
+// arguments, lego's exec provider passes the fqdn and value or with EXEC_MODE=RAW the
+// domain, token and key authorization. Without arguments the environment variables
+// of certbot's manual hooks are used.
+func challengeArgs(args []string) (string, string, error) {
+	switch len(args) {
+	case 0:
+		domain, validation := os.Getenv("CERTBOT_DOMAIN"), os.Getenv("CERTBOT_VALIDATION")
+		if domain == "" || validation == "" {
+			return "", "", fmt.Errorf("either pass the fqdn and value or set CERTBOT_DOMAIN and CERTBOT_VALIDATION")
+		}
+		return dnsRepo.ChallengeName(domain), validation, nil
+	case 2:
+		return dnsRepo.ChallengeName(args[0]), args[1], nil
+	case 3:
+		return dnsRepo.ChallengeName(args[0]), dnsRepo.ChallengeValue(args[2]), nil
+	}
+	return "", "", fmt.Errorf("expected the fqdn and value, or the domain, token and key authorization, got %d arguments", len(args))
+}
+
+// challengeRecord returns the challenge record and the zone and repository it belongs to.
+// CNAMEs of the challenge name are followed unless --no-follow is set.
+func challengeRecord(cmd *cobra.Command, args []string) (dnsRepo.DnsRecord, string, dnsRepo.DnsRepository, error) {
+	name, value, err := challengeArgs(args)
+	if err != nil {
+		return dnsRepo.DnsRecord{}, "", nil, err
+	}
+
+	if !acmeNoFollow {
+		target, err := dnsRepo.FollowChallengeCNAME(cmd.Context(), name)
+		if err != nil {
+			return dnsRepo.DnsRecord{}, "", nil, err
+		}
+		if target != name {
+			cmd.Printf("%s is delegated to %s\n", name, target)
+			name = target
+		}
+	}
+
+	recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), name, zone, provider, token)
+	if err != nil {
+		return dnsRepo.DnsRecord{}, "", nil, err
+	}
+	return dnsRepo.DnsRecord{Name: name, Type: "TXT", Content: value, TTL: acmeTTL}, recordZone, repo, nil
+}
+
+// acmeCmd represents the acme command
+var acmeCmd = &cobra.Command{
+	Use:   "acme",
+	Short: "Hooks for ACME DNS-01 challenges of certbot and lego",
+	Long: `With the subcommands you can create and remove the TXT records of ACME DNS-01
+challenges, e.g. to issue wildcard certificates. If _acme-challenge of the domain is a
+CNAME to another zone, the record is created at the end of the CNAME chain.
+The challenge is taken from the arguments or from the environment variables of certbot.
+For example with certbot:
+
+  certbot certonly --manual --preferred-challenges dns -d '*.example.com' \
+    --manual-auth-hook 'akatran dns acme present' \
+    --manual-cleanup-hook 'akatran dns acme cleanup'
+
+lego's exec provider calls a program with present or cleanup as first argument, so use
+a small script like this as EXEC_PATH, the default and the RAW mode are both supported:
+
+  #!/bin/sh
+  exec akatran dns acme "$@"
+
+  EXEC_PATH=/usr/local/bin/akatran-lego lego --dns exec -d '*.example.com' run
+`,
+}
+
+// acmePresentCmd represents the acme present command
+var acmePresentCmd = &cobra.Command{
+	Use:   "present [flags] [fqdn value | domain token key_authorization]",
+	Short: "Create the TXT record of an ACME challenge",
+	Args:  cobra.RangeArgs(0, 3),
+	Long: `With this command you can create the TXT record of an ACME DNS-01 challenge.
+Other values of the record, e.g. of a certificate for the domain and its wildcard,
+are kept. The command returns once all authoritative name servers serve the value,
+--wait changes how long to wait at most and --wait=0 returns right away.
+For example:
+
+  akatran dns acme present _acme-challenge.example.com. gfj9Xq...Rg85nM
+  akatran dns acme present example.com token key-authorization
+  CERTBOT_DOMAIN=example.com CERTBOT_VALIDATION=gfj9Xq...Rg85nM akatran dns acme present
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - ACME] - ")
+
+		if !cmd.Flags().Changed("wait") {
+			waitTimeout = dnsRepo.DefaultPropagationTimeout
+		}
+
+		record, recordZone, repo, err := challengeRecord(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		action, _, err := dnsRepo.AddValue(cmd.Context(), repo, record)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		if action == dnsRepo.SetUnchanged {
+			cmd.Printf("The challenge %s already has the value %s!\n", record.Name, record.Content)
+		} else {
+			cmd.Printf("Challenge %s with value %s created!\n", record.Name, record.Content)
+		}
+
+		return waitForPropagation(cmd, recordZone, record)
+	},
+}
+
+// acmeCleanupCmd represents the acme cleanup command
+var acmeCleanupCmd = &cobra.Command{
+	Use:   "cleanup [flags] [fqdn value | domain token key_authorization]",
+	Short: "Remove the TXT record of an ACME challenge",
+	Args:  cobra.RangeArgs(0, 3),
+	Long: `With this command you can remove the TXT record value of an ACME DNS-01 challenge
+after the validation. Only the value of the challenge is removed, the values of other
+challenges for the same name are kept.
+For example:
+
+  akatran dns acme cleanup _acme-challenge.example.com. gfj9Xq...Rg85nM
+  CERTBOT_DOMAIN=example.com CERTBOT_VALIDATION=gfj9Xq...Rg85nM akatran dns acme cleanup
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - ACME] - ")
+
+		record, _, repo, err := challengeRecord(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		deleted, _, err := dnsRepo.RemoveValue(cmd.Context(), repo, record)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		if len(deleted) == 0 {
+			cmd.Printf("The challenge %s does not have the value %s!\n", record.Name, record.Content)
+			return nil
+		}
+		cmd.Printf("Challenge %s with value %s removed!\n", record.Name, record.Content)
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(acmeCmd)
+	acmeCmd.AddCommand(acmePresentCmd, acmeCleanupCmd)
+
+	for _, cmd := range []*cobra.Command{acmePresentCmd, acmeCleanupCmd} {
+		addZoneFlag(cmd)
+		cmd.Flags().BoolVar(&acmeNoFollow, "no-follow", false, "Do not follow a CNAME of the challenge name")
+	}
+	acmePresentCmd.Flags().Uint32Var(&acmeTTL, "ttl", dnsRepo.DefaultChallengeTTL, "The TTL of the challenge record")
+	addWaitFlag(acmePresentCmd)
+	// present waits by default, so the ACME client does not ask for validation too early.
+	acmePresentCmd.Flags().Lookup("wait").DefValue = dnsRepo.DefaultPropagationTimeout.String()
+}
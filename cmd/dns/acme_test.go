@@ -0,0 +1,67 @@
+package dns
+
+import "testing"
+
+func TestChallengeArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "lego fqdn and value",
+			args:      []string{"_acme-challenge.example.com.", "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM"},
+			wantName:  "_acme-challenge.example.com",
+			wantValue: "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM",
+		},
+		{
+			name:      "lego raw mode",
+			args:      []string{"example.com.", "evaGxfADs6pSRb2LAv9IZf17Dt3juxGJ-PCt92wr-oA", "evaGxfADs6pSRb2LAv9IZf17Dt3juxGJ-PCt92wr-oA.9jg46WB3rR_AHD-EBXdN7cBkH1WOu0tA3M9fm21mqTI"},
+			wantName:  "_acme-challenge.example.com",
+			wantValue: "lCM7cZyQXcVHK2nnW3jjAhNT3Fvm18UN-kWZZknKoYM",
+		},
+		{
+			name:      "wildcard domain",
+			args:      []string{"*.example.com", "value"},
+			wantName:  "_acme-challenge.example.com",
+			wantValue: "value",
+		},
+		{
+			name:      "certbot environment",
+			env:       map[string]string{"CERTBOT_DOMAIN": "www.example.com", "CERTBOT_VALIDATION": "value"},
+			wantName:  "_acme-challenge.www.example.com",
+			wantValue: "value",
+		},
+		{
+			name:    "certbot environment without validation",
+			env:     map[string]string{"CERTBOT_DOMAIN": "www.example.com", "CERTBOT_VALIDATION": ""},
+			wantErr: true,
+		},
+		{
+			name:    "only the fqdn",
+			args:    []string{"_acme-challenge.example.com."},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CERTBOT_DOMAIN", "")
+			t.Setenv("CERTBOT_VALIDATION", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			name, value, err := challengeArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("challengeArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("challengeArgs() = %q, %q, want %q, %q", name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+// addValueCmd represents the add-value command
+var addValueCmd = &cobra.Command{
+	Use:   "add-value [flags] dns_record",
+	Short: "Add a value to the records with the same name and type",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can add a value to a record set like round-robin A records or
+several TXT records, without touching the other values.
+Nothing is changed if the value already exists. Without --ttl the TTL of the existing values is used.
+For example:
+
+  akatran dns add-value api.example.com --content 198.51.100.13
+  akatran dns add-value example.com --type TXT --content "google-site-verification=abc"
+  akatran dns add-value example.com --type MX --content mail2.example.com --priority 20
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - ADD-VALUE] - ")
+
+		dnsRecord := args[0]
+
+		recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
+		if err != nil {
+			return err
+		}
+
+		record, err := buildNewRecord(cmd, dnsRecord)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		action, values, err := dnsRepo.AddValue(cmd.Context(), repo, record)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		if action == dnsRepo.SetUnchanged {
+			cmd.Printf("The %s record %s already has the value %s!\n", record.Type, dnsRecord, record.Content)
+		} else {
+			cmd.Printf("Value %s added to the %s record %s (%d values in total)!\n", record.Content, record.Type, dnsRecord, len(values))
+		}
+
+		if err := waitForPropagation(cmd, recordZone, record); err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(addValueCmd)
+
+	addZoneFlag(addValueCmd)
+	addWaitFlag(addValueCmd)
+	addRecordFlags(addValueCmd)
+	addValueCmd.MarkFlagRequired("content")
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var applyPlanFile string
+var applyYes bool
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply [flags] [file]",
+	Short: "Make a zone match a YAML file",
+	Args:  cobra.MaximumNArgs(1),
+	Long: `With this command you can apply the changes shown by plan. Either pass the
+YAML file of the zone, or a plan saved with plan --out. A saved plan is only applied
+if the zone did not change since the plan was created.
+For example:
+
+  akatran dns apply example.com.yaml
+  akatran dns apply example.com.yaml --yes
+  akatran dns apply --plan example.com.plan
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - APPLY] - ")
+
+		if (len(args) == 0) == (applyPlanFile == "") {
+			return fmt.Errorf("either a file or --plan has to be provided")
+		}
+
+		var plan *dnsRepo.Plan
+		var repo dnsRepo.DnsRepository
+		var err error
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		if applyPlanFile != "" {
+			plan, err = dnsRepo.LoadPlan(applyPlanFile)
+			if err != nil {
+				return err
+			}
+
+			repo, err = dnsRepo.GetRepoFromViperOrFlag(plan.Zone, provider, token)
+			if err != nil {
+				return err
+			}
+
+			if err := plan.Verify(cmd.Context(), repo); err != nil {
+				return fmt.Errorf("%w, run plan again", err)
+			}
+		} else {
+			spec, err := dnsRepo.LoadZoneSpec(args[0])
+			if err != nil {
+				return err
+			}
+
+			repo, err = dnsRepo.GetRepoFromViperOrFlag(spec.Zone, provider, token)
+			if err != nil {
+				return err
+			}
+
+			plan, err = dnsRepo.NewPlan(cmd.Context(), repo, spec)
+			if err != nil {
+				return err
+			}
+		}
+
+		spinner.Stop()
+		cmd.Println(plan)
+
+		if len(plan.Changes) == 0 {
+			return nil
+		}
+
+		// A saved plan was already reviewed when it was created.
+		if applyPlanFile == "" && !applyYes && !confirm(cmd, "\nDo you want to apply these changes?") {
+			cmd.Println("Apply cancelled.")
+			return nil
+		}
+
+		spinner.Start()
+		results := plan.Apply(cmd.Context(), repo, concurrency)
+		spinner.Stop()
+		if err := results.Err(); err != nil {
+			cmd.Println()
+			return printResults(cmd, results)
+		}
+
+		cmd.Printf("\nApplied %d changes to %s!\n", len(plan.Changes), plan.Zone)
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(applyCmd)
+
+	addConcurrencyFlag(applyCmd)
+
+	applyCmd.Flags().StringVar(&applyPlanFile, "plan", "", "Apply a plan saved with plan --out")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Apply the changes without asking for confirmation")
+}
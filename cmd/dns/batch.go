@@ -0,0 +1,107 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var batchStopOnError bool
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch [flags] file|-",
+	Short: "Run many record operations from a CSV or JSON file",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can create, update, set and delete many records at once.
+The operations are read from a file or with - from stdin, either as CSV with a header row,
+as JSON array or as one JSON object per line. Every operation has an op (create, update, set
+or delete), a name, a type and a content, optionally a ttl, priority, proxied and comment.
+The zone of every record is detected from its name unless --zone is given.
+The operations run at the same time, operations for the same name and type one after another
+in the order of the file. The result of every line is reported. By default all operations
+are tried, with --stop-on-error nothing new is started after the first failure.
+For example:
+
+  cat records.csv
+  op,name,type,content,ttl
+  create,app.dev.example.com,A,198.51.100.20,300
+  set,api.dev.example.com,CNAME,app.dev.example.com,
+  delete,old.dev.example.com,A,,
+
+  akatran dns batch records.csv
+  akatran dns batch records.jsonl --stop-on-error
+  generate-records | akatran dns batch - --json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - BATCH] - ")
+
+		var input io.Reader = cmd.InOrStdin()
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			input = file
+		}
+
+		entries, err := dnsRepo.ParseBatch(input)
+		if err != nil {
+			return err
+		}
+
+		resolve := dnsRepo.NewZoneResolver(zone, provider, token)
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		cmd.Printf("Running %d operations\n", len(entries))
+
+		results := dnsRepo.RunBatch(cmd.Context(), entries, resolve, concurrency, batchStopOnError)
+		spinner.Stop()
+
+		if jsonOutput {
+			fmt.Fprint(cmd.OutOrStdout(), results.AsJsonString())
+			return results.Err()
+		}
+
+		cmd.Print(results.AsTableString())
+		cmd.Println(results.Summary())
+		return results.Err()
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(batchCmd)
+
+	addZoneFlag(batchCmd)
+	addConcurrencyFlag(batchCmd)
+	batchCmd.Flags().BoolVar(&batchStopOnError, "stop-on-error", false, "Do not start any operation after the first one failed")
+	batchCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the result of every line as JSON")
+}
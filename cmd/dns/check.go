@@ -0,0 +1,185 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var checkType string
+var checkContent []string
+var checkNameservers []string
+var checkResolvers []string
+var waitTimeout time.Duration
+
+// addWaitFlag adds the --wait flag, which takes an optional timeout.
+func addWaitFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&waitTimeout, "wait", 0, "Wait until all authoritative name servers serve the change, e.g. --wait=2m")
+	cmd.Flags().Lookup("wait").NoOptDefVal = dnsRepo.DefaultPropagationTimeout.String()
+}
+
+// waitForPropagation blocks until all authoritative name servers of the zone serve the record.
+// Proxied records are skipped, because the name servers answer with the addresses of the
+// proxy instead of the content of the record.
+func waitForPropagation(cmd *cobra.Command, recordZone string, record dnsRepo.DnsRecord) error {
+	if waitTimeout <= 0 {
+		return nil
+	}
+	if record.Proxied != nil && *record.Proxied {
+		cmd.Printf("DNS record %s is proxied, so the name servers do not serve its content, not waiting for it.\n", record.Name)
+		return nil
+	}
+
+	cmd.Printf("Waiting up to %s for %s to propagate...\n", waitTimeout, record.Name)
+
+	spinner.Start()
+	defer spinner.Stop()
+
+	checker := dnsRepo.NewPropagationChecker(recordZone)
+	results, err := checker.Wait(cmd.Context(), recordZone, record.Name, record.Type, []string{record.Content}, waitTimeout)
+	spinner.Stop()
+	if err != nil {
+		if results != nil {
+			cmd.Print(results.AsTableString())
+		}
+		return err
+	}
+
+	cmd.Printf("DNS record %s is served by all %d authoritative name servers!\n", record.Name, len(results))
+	return nil
+}
+
+// updatedRecord returns the record to wait for after an update. The proxy state is taken
+// from the updated records, as an update without --proxied keeps the current one.
+func updatedRecord(record dnsRepo.DnsRecord, results dnsRepo.SetResultList) dnsRepo.DnsRecord {
+	for _, result := range results {
+		if result.Record.Proxied != nil && *result.Record.Proxied {
+			record.Proxied = result.Record.Proxied
+		}
+	}
+	return record
+}
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [flags] dns_record",
+	Short: "Check whether a DNS record is propagated",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can check whether the name servers serve a record.
+The authoritative name servers of the zone are queried directly, together with the
+public resolvers of propagation::resolvers in the config file or the --resolver flag.
+By default the content is expected to match the records at the provider, proxied
+records need the expected addresses of the proxy in --content.
+The name servers can be overridden with dns::<domain>::nameservers or --nameserver.
+For example:
+
+  akatran dns check www.example.com
+  akatran dns check www.example.com --type AAAA --resolver 1.1.1.1 --resolver 8.8.8.8
+  akatran dns check www.example.com --content 198.51.100.10 --wait=2m
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - CHECK] - ")
+
+		name := strings.TrimSuffix(args[0], ".")
+
+		recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), name, zone, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		expected := checkContent
+		if len(expected) == 0 {
+			records, err := repo.ListRecords(cmd.Context(), checkType)
+			if err != nil {
+				return err
+			}
+			expected = make([]string, 0)
+			for _, record := range records {
+				if !strings.EqualFold(record.Name, name) {
+					continue
+				}
+				if record.Proxied != nil && *record.Proxied {
+					return fmt.Errorf("%s record %s is proxied, so the name servers do not serve its content, pass the expected addresses with --content", checkType, name)
+				}
+				expected = append(expected, record.Content)
+			}
+		}
+
+		checker := dnsRepo.NewPropagationChecker(recordZone)
+		if len(checkNameservers) > 0 {
+			checker.Nameservers = checkNameservers
+		}
+		if len(checkResolvers) > 0 {
+			checker.Resolvers = checkResolvers
+		}
+
+		var results dnsRepo.PropagationResultList
+		if waitTimeout > 0 {
+			results, err = checker.Wait(cmd.Context(), recordZone, name, checkType, expected, waitTimeout)
+			if err == nil {
+				// The resolvers are not waited for, but still reported.
+				results, err = checker.Check(cmd.Context(), recordZone, name, checkType, expected)
+			}
+		} else {
+			results, err = checker.Check(cmd.Context(), recordZone, name, checkType, expected)
+		}
+		spinner.Stop()
+		if results == nil && err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			fmt.Fprint(cmd.OutOrStdout(), results.AsJsonString())
+		} else {
+			cmd.Printf("Expected %s %s: %s\n", checkType, name, strings.Join(expected, ", "))
+			fmt.Fprint(cmd.OutOrStdout(), results.AsTableString())
+		}
+		if err != nil {
+			return err
+		}
+		if !results.Propagated() {
+			return fmt.Errorf("%s record %s is not propagated yet", checkType, name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(checkCmd)
+
+	addZoneFlag(checkCmd)
+	addWaitFlag(checkCmd)
+	checkCmd.Flags().StringVarP(&checkType, "type", "t", "A", "The type of the DNS record")
+	checkCmd.Flags().StringSliceVarP(&checkContent, "content", "c", nil, "The expected content (default is the content at the provider)")
+	checkCmd.Flags().StringSliceVar(&checkNameservers, "nameserver", nil, "Query these name servers instead of the authoritative ones of the zone")
+	checkCmd.Flags().StringSliceVar(&checkResolvers, "resolver", nil, "Public resolvers to query in addition, e.g. 1.1.1.1")
+	checkCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+}
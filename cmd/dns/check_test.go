@@ -0,0 +1,59 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/spf13/cobra"
+)
+
+func TestWaitForPropagation(t *testing.T) {
+	proxied := true
+
+	tests := []struct {
+		name    string
+		record  dnsRepo.DnsRecord
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "proxied records are skipped",
+			record: dnsRepo.DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Proxied: &proxied},
+			want:   "DNS record www.example.com is proxied",
+		},
+		{
+			name:    "other records are waited for",
+			record:  dnsRepo.DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"},
+			want:    "Waiting up to 200ms for www.example.com to propagate",
+			wantErr: true,
+		},
+	}
+
+	// Nothing listens on the name server, so waiting for a record fails by the timeout.
+	viper.Set("dns::example.com::nameservers", []string{"127.0.0.1:1"})
+	t.Cleanup(func() { viper.Set("dns::example.com::nameservers", nil) })
+	waitTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { waitTimeout = 0 })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&out)
+			cmd.SetContext(context.Background())
+
+			err := waitForPropagation(cmd, "example.com", tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitForPropagation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("waitForPropagation() printed %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
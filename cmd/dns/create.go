@@ -22,19 +22,11 @@ THE SOFTWARE.
 package dns
 
 import (
-	"fmt"
-	"net/url"
-	"strings"
-
 	dnsRepo "github.com/akatranlp/akatran/internal/dns"
 	"github.com/akatranlp/akatran/internal/spinner"
-	"github.com/akatranlp/akatran/internal/utils"
 	"github.com/spf13/cobra"
 )
 
-var recordType string
-var recordContent string
-
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create [flags] dns_record",
@@ -44,67 +36,44 @@ var createCmd = &cobra.Command{
 if you don't provide the content flag, your public IP-Adress from the record type will be used. 
 For example:
 
-  akatran dns [--token <cloudflare-token>] [--provider <cloudflare>] create www.example.com [--content content] [--type A|AAAA|CNAME]
+  akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] create www.example.com [--content content] [--type A|AAAA|CNAME|MX|TXT|NS|SRV|CAA|PTR]
   akatran dns create www.example.com
   akatran dns create www.example.com --type CNAME --content example.com
+  akatran dns create example.com --type MX --content mail.example.com --priority 10
+  akatran dns create example.com --type TXT --content "v=spf1 mx -all"
+  akatran dns create _sip._tcp.example.com --type SRV --content sip.example.com --priority 10 --weight 5 --port 5060
+  akatran dns create example.com --type CAA --tag issue --content letsencrypt.org
+  akatran dns create www.example.com --ttl 300 --proxied --comment "web server" --tags env:prod
+  akatran dns create host.lab.example.com --zone lab.example.com --content 192.0.2.1
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SetErrPrefix("Error: [DNS - CREATE] - ")
 
 		dnsRecord := args[0]
 
-		parts := strings.Split(dnsRecord, ".")
-		if len(parts) < 2 {
-			return fmt.Errorf("invalid dns record")
-		}
-
-		domain := strings.Join(parts[len(parts)-2:], ".")
-
-		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
 		if err != nil {
 			return err
 		}
 
-		switch recordType {
-		case "A":
-			ip := utils.GetIPv4Address(recordContent)
-			if ip == nil {
-				return fmt.Errorf("invalid IPv4 address")
-			}
-			recordContent = ip.String()
-
-		case "AAAA":
-			ip := utils.GetIPv6Address(recordContent)
-			if ip == nil {
-				return fmt.Errorf("invalid IPv6 address")
-			}
-			recordContent = ip.String()
-		case "CNAME":
-			if recordContent == "" {
-				return fmt.Errorf("content is required for CNAME records")
-			}
-			domain, err := url.Parse(recordContent)
-			if err != nil {
-				return err
-			}
-			recordContent = domain.Hostname()
-		default:
-			return fmt.Errorf("invalid record type")
+		record, err := buildNewRecord(cmd, dnsRecord)
+		if err != nil {
+			return err
 		}
 
 		spinner.Start()
 		defer spinner.Stop()
 
-		if err := repo.CreateRecord(cmd.Context(), dnsRepo.DnsRecord{
-			Name:    dnsRecord,
-			Type:    recordType,
-			Content: recordContent,
-		}); err != nil {
+		if err := repo.CreateRecord(cmd.Context(), record); err != nil {
 			return err
 		}
 
 		spinner.Stop()
 		cmd.Printf("DNS record %s created!\n", dnsRecord)
+
+		if err := waitForPropagation(cmd, recordZone, record); err != nil {
+			return err
+		}
 		return nil
 	},
 }
@@ -112,6 +81,7 @@ For example:
 func init() {
 	DnsCmd.AddCommand(createCmd)
 
-	createCmd.Flags().StringVarP(&recordType, "type", "t", "A", "The type of the DNS record")
-	createCmd.Flags().StringVarP(&recordContent, "content", "c", "", "The content of the DNS record")
+	addZoneFlag(createCmd)
+	addWaitFlag(createCmd)
+	addRecordFlags(createCmd)
 }
@@ -0,0 +1,220 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"time"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/spf13/cobra"
+)
+
+var ddnsTypes []string
+var ddnsInterval time.Duration
+var ddnsRecheck time.Duration
+var ddnsStateFile string
+var ddnsOnce bool
+var ddnsIPv4 string
+var ddnsIPv6 string
+
+// ddnsCmd represents the ddns command
+var ddnsCmd = &cobra.Command{
+	Use:   "ddns [flags] [dns_record...]",
+	Short: "Keep A and AAAA records pointed to the public IP address",
+	Long: `With this command you can keep the A and AAAA records of your hosts pointed
+to the public IP addresses of this machine. The addresses are checked on every interval
+and only records that drifted are updated. Missing records are created.
+The hosts are taken from the arguments or from ddns::hosts in the config file.
+For example:
+
+  akatran dns ddns home.example.com vpn.example.com --type A,AAAA --interval 5m
+  akatran dns ddns --once
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - DDNS] - ")
+
+		hosts := args
+		if len(hosts) == 0 {
+			hosts = viper.GetStringSlice("ddns::hosts")
+		}
+		if len(hosts) == 0 {
+			return fmt.Errorf("no hosts provided")
+		}
+
+		for _, type_ := range ddnsTypes {
+			if type_ != "A" && type_ != "AAAA" {
+				return fmt.Errorf("invalid record type for ddns: %s", type_)
+			}
+		}
+
+		if ddnsInterval <= 0 {
+			return fmt.Errorf("interval has to be positive")
+		}
+
+		if ddnsStateFile == "" {
+			stateDir, err := utils.StateDir(cmd.Root().Name())
+			if err != nil {
+				return err
+			}
+			ddnsStateFile = filepath.Join(stateDir, "ddns.json")
+		}
+
+		state, err := dnsRepo.LoadDDNSState(ddnsStateFile)
+		if err != nil {
+			return err
+		}
+
+		d := &ddns{
+			logger: log.New(cmd.OutOrStdout(), "", log.LstdFlags),
+			hosts:  hosts,
+			state:  state,
+			repos:  make(map[string]dnsRepo.DnsRepository),
+		}
+
+		if ddnsOnce {
+			if failed := d.run(cmd.Context()); failed > 0 {
+				return fmt.Errorf("failed to sync %d records", failed)
+			}
+			return nil
+		}
+
+		ticker := time.NewTicker(ddnsInterval)
+		defer ticker.Stop()
+
+		for {
+			d.run(cmd.Context())
+
+			select {
+			case <-cmd.Context().Done():
+				d.logger.Println("shutting down")
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+type ddns struct {
+	logger *log.Logger
+	hosts  []string
+	state  *dnsRepo.DDNSState
+	// repos caches the repository of every host, so the zone is only looked up once.
+	repos map[string]dnsRepo.DnsRepository
+}
+
+func (d *ddns) repo(ctx context.Context, host string) (dnsRepo.DnsRepository, error) {
+	if repo, ok := d.repos[host]; ok {
+		return repo, nil
+	}
+
+	_, repo, err := dnsRepo.ResolveZone(ctx, host, "", provider, token)
+	if err != nil {
+		return nil, err
+	}
+	d.repos[host] = repo
+	return repo, nil
+}
+
+func currentAddress(ctx context.Context, type_ string) (string, error) {
+	var ip net.IP
+	var err error
+	if type_ == "A" {
+		ip, err = utils.GetIPv4Address(ctx, ddnsIPv4)
+	} else {
+		ip, err = utils.GetIPv6Address(ctx, ddnsIPv6)
+	}
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// run syncs all hosts once and returns the number of records that could not be synced.
+func (d *ddns) run(ctx context.Context) int {
+	failed := 0
+	changed := false
+
+	for _, type_ := range ddnsTypes {
+		address, err := currentAddress(ctx, type_)
+		if err != nil {
+			d.logger.Printf("could not determine the public address for %s records: %v", type_, err)
+			failed += len(d.hosts)
+			continue
+		}
+
+		for _, host := range d.hosts {
+			if ctx.Err() != nil {
+				return failed
+			}
+
+			if d.state.IsCurrent(host, type_, address, ddnsRecheck) {
+				continue
+			}
+
+			repo, err := d.repo(ctx, host)
+			if err != nil {
+				d.logger.Printf("%s: %v", host, err)
+				failed++
+				continue
+			}
+
+			action, err := dnsRepo.SyncAddress(ctx, repo, host, type_, address)
+			if err != nil {
+				d.logger.Printf("%s %s: %v", type_, host, err)
+				failed++
+				continue
+			}
+			if action != dnsRepo.DDNSUnchanged {
+				d.logger.Printf("%s %s %s: %s", type_, host, action, address)
+			}
+
+			d.state.Set(host, type_, address)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := d.state.Save(); err != nil {
+			d.logger.Printf("failed to save state: %v", err)
+		}
+	}
+	return failed
+}
+
+func init() {
+	DnsCmd.AddCommand(ddnsCmd)
+
+	ddnsCmd.Flags().StringSliceVarP(&ddnsTypes, "type", "t", []string{"A"}, "The record types to keep up to date (A, AAAA)")
+	ddnsCmd.Flags().DurationVar(&ddnsInterval, "interval", 5*time.Minute, "How often the public addresses are checked")
+	ddnsCmd.Flags().DurationVar(&ddnsRecheck, "recheck", time.Hour, "How often the records are compared with the provider even if the address did not change")
+	ddnsCmd.Flags().StringVar(&ddnsStateFile, "state", "", "The state file (default is $XDG_STATE_HOME/akatran/ddns.json)")
+	ddnsCmd.Flags().StringVar(&ddnsIPv4, "ipv4", "", "Use this IPv4 address instead of detecting it")
+	ddnsCmd.Flags().StringVar(&ddnsIPv6, "ipv6", "", "Use this IPv6 address instead of detecting it")
+	ddnsCmd.Flags().BoolVar(&ddnsOnce, "once", false, "Sync the records once and exit, e.g. when run from cron")
+}
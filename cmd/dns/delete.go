@@ -22,15 +22,14 @@ THE SOFTWARE.
 package dns
 
 import (
-	"fmt"
-	"strings"
-
 	dnsRepo "github.com/akatranlp/akatran/internal/dns"
 	"github.com/akatranlp/akatran/internal/spinner"
 	"github.com/spf13/cobra"
 )
 
 var deleteRecordType string
+var deleteRecordContent string
+var deleteRecordID string
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -38,56 +37,63 @@ var deleteCmd = &cobra.Command{
 	Short: "Delete a DNS record",
 	Args:  cobra.ExactArgs(1),
 	Long: `With the subcommands you can delete the given record of your domain.
+All records with the name and type are deleted, unless --content or --id select single records.
+Every record is deleted on its own and the result of each record is reported, --json
+prints it for scripts that only want to retry the failed records.
 For example:
 
-  akatran dns [--token <cloudflare-token>] [--provider <cloudflare>] delete www.example.com --type A|AAAA|CNAME
+  akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] delete www.example.com --type A|AAAA|CNAME|MX|TXT|NS|SRV|CAA|PTR
   akatran dns delete www.example.com --type A
+  akatran dns delete _sip._tcp.example.com --type SRV
+  akatran dns delete host.lab.example.com --zone lab.example.com --type A
+  akatran dns delete api.example.com --type A --content 198.51.100.12
+  akatran dns delete api.example.com --type A --id 3f2a9c
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - DELETE] - ")
+
 		dnsRecord := args[0]
 
-		parts := strings.Split(dnsRecord, ".")
-		if len(parts) < 2 {
-			return fmt.Errorf("invalid dns record")
+		_, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
+		if err != nil {
+			return err
 		}
 
-		domain := strings.Join(parts[len(parts)-2:], ".")
-
-		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
-		if err != nil {
+		if err := dnsRepo.ValidateRecordTypes(deleteRecordType); err != nil {
 			return err
 		}
 
 		spinner.Start()
 		defer spinner.Stop()
 
-		switch deleteRecordType {
-		case "A":
-		case "AAAA":
-		case "CNAME":
-		default:
-			return fmt.Errorf("invalid record type")
-		}
-
-		dnsRecords, err := repo.DeleteRecord(cmd.Context(), dnsRepo.DnsRecord{
-			Name: dnsRecord,
-			Type: deleteRecordType,
+		records, err := dnsRepo.FindRecords(cmd.Context(), repo, dnsRepo.DnsRecord{
+			ID:      deleteRecordID,
+			Name:    dnsRecord,
+			Type:    deleteRecordType,
+			Content: deleteRecordContent,
 		})
 		if err != nil {
 			return err
 		}
+		if len(records) == 0 {
+			return dnsRepo.ErrRecordNotFound
+		}
 
+		results := dnsRepo.DeleteRecords(cmd.Context(), repo, records, concurrency)
 		spinner.Stop()
 
-		cmd.Println("The following DNS records were deleted!")
-		cmd.Println(dnsRecords.AsTableString())
-		return nil
+		return printResults(cmd, results)
 	},
 }
 
 func init() {
 	DnsCmd.AddCommand(deleteCmd)
 
+	addZoneFlag(deleteCmd)
 	deleteCmd.Flags().StringVarP(&deleteRecordType, "type", "t", "", "Record type")
 	deleteCmd.MarkFlagRequired("type")
+	deleteCmd.Flags().StringVarP(&deleteRecordContent, "content", "c", "", "Only delete records with this content")
+	deleteCmd.Flags().StringVar(&deleteRecordID, "id", "", "Only delete the record with this ID")
+	deleteCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the result of every record as JSON")
+	addConcurrencyFlag(deleteCmd)
 }
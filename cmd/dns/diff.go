@@ -0,0 +1,125 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/spf13/cobra"
+)
+
+var diffFrom string
+var diffTo string
+var diffIncludeNS bool
+
+// addProfileFlags adds the flags to select the source and target of a comparison.
+func addProfileFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&diffFrom, "from", "", "The profile of the source provider (default is the provider configured for the domain)")
+	cmd.Flags().StringVar(&diffTo, "to", "", "The profile of the target provider (default is the provider configured for the domain)")
+	cmd.Flags().BoolVar(&diffIncludeNS, "include-ns", false, "Also compare the NS records of the zone apex, which every provider sets to its own name servers")
+}
+
+// profileRepos returns the repositories of the domain for the --from and --to profiles.
+func profileRepos(domain string) (dnsRepo.DnsRepository, dnsRepo.DnsRepository, error) {
+	if diffFrom == diffTo {
+		return nil, nil, fmt.Errorf("--from and --to have to select different profiles")
+	}
+
+	repo := func(profile string) (dnsRepo.DnsRepository, error) {
+		if profile == "" {
+			return dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		}
+		return dnsRepo.GetRepoFromProfile(domain, profile)
+	}
+
+	from, err := repo(diffFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := repo(diffTo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [flags] domain",
+	Short: "Compare the DNS records of a domain at two providers",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can compare the records of a domain at two providers,
+e.g. to confirm that both sides match before switching the name servers.
+The providers are selected by profiles in the config file:
+
+  profiles:
+    old:
+      provider: hetzner
+      token: hetzner-dns-api-token
+    new:
+      provider: cloudflare
+      token: cloudflare-api-token
+
+Records are reported as missing if they only exist at the source, as extra if they
+only exist at the target and as changed if their TTL or proxy state differs.
+The NS records of the domain itself are skipped, as each provider serves the zone
+with its own name servers, unless --include-ns is set.
+For example:
+
+  akatran dns diff example.com --from old --to new
+  akatran dns diff example.com --to new --json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - DIFF] - ")
+
+		domain := args[0]
+
+		from, to, err := profileRepos(domain)
+		if err != nil {
+			return err
+		}
+
+		diffs, err := dnsRepo.DiffZones(cmd.Context(), domain, from, to, diffIncludeNS)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			fmt.Fprint(cmd.OutOrStdout(), diffs.AsJsonString())
+			return nil
+		}
+		if len(diffs) == 0 {
+			cmd.Printf("The records of %s match!\n", domain)
+			return nil
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diffs.AsTableString())
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(diffCmd)
+
+	addProfileFlags(diffCmd)
+	diffCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+}
@@ -22,11 +22,20 @@ THE SOFTWARE.
 package dns
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/akatranlp/akatran/internal/viper"
 	"github.com/spf13/cobra"
 )
 
 var token string
 var provider string
+var zone string
+var concurrency int
 
 // DnsCmd represents the Dns command
 var DnsCmd = &cobra.Command{
@@ -35,14 +44,64 @@ var DnsCmd = &cobra.Command{
 	Long: `With the subcommands you can list, create, update or delete
 given DNS-records for your domains. For example:
 
-	akatran dns [--token <cloudflare-token>] [--provider <cloudflare>] list example.com 
+	akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] list example.com 
 	akatran dns create www.example.com --type A --content 127.0.0.1
 	akatran dns update www.example.com --content 192.168.0.1
 	akatran dns delete www.example.com
+
+Every change is recorded in an audit log, see history and undo. The log is written to
+$XDG_STATE_HOME/akatran/audit.jsonl, audit::file in the config file changes the path and
+audit::enabled set to false disables it.
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if viper.IsSet("audit::enabled") && !viper.GetBool("audit::enabled") {
+			return nil
+		}
+
+		path, err := auditLogPath(cmd)
+		if err != nil {
+			return err
+		}
+		dnsRepo.EnableAuditLog(dnsRepo.NewAuditLog(path, os.Args))
+		return nil
+	},
+}
+
+// auditLogPath returns the path of the audit log, audit::file or audit.jsonl in the state directory.
+func auditLogPath(cmd *cobra.Command) (string, error) {
+	if path := viper.GetString("audit::file"); path != "" {
+		return path, nil
+	}
+
+	stateDir, err := utils.StateDir(cmd.Root().Name())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "audit.jsonl"), nil
+}
+
+func addZoneFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&zone, "zone", "", "The zone of the DNS record (default is detected from the record name)")
+}
+
+func addConcurrencyFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&concurrency, "concurrency", dnsRepo.DefaultConcurrency, "The number of provider requests to run at the same time")
+}
+
+// printResults prints the result of every record of a bulk operation and returns an
+// error if not all of them succeeded. JSON goes to stdout, so it can be piped.
+func printResults(cmd *cobra.Command, results dnsRepo.RecordResultList) error {
+	if jsonOutput {
+		fmt.Fprint(cmd.OutOrStdout(), results.AsJsonString())
+		return results.Err()
+	}
+
+	cmd.Print(results.AsTableString())
+	cmd.Println(results.Summary())
+	return results.Err()
 }
 
 func init() {
-	DnsCmd.PersistentFlags().StringVar(&provider, "provider", "", "DNS provider")
+	DnsCmd.PersistentFlags().StringVar(&provider, "provider", "", "DNS provider (cloudflare, hetzner, rfc2136, zonefile, memory)")
 	DnsCmd.PersistentFlags().StringVar(&token, "token", "", "API token")
 }
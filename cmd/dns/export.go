@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+var exportOutput string
+var exportGeneric bool
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [flags] domain",
+	Short: "Export all DNS records of a domain as zone file",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can export all records of a domain as RFC 1035 zone file,
+e.g. for backups or to migrate the zone to another provider.
+Cloudflare zones are exported with the native export of Cloudflare.
+For example:
+
+  akatran dns export example.com --format bind
+  akatran dns export example.com --output example.com.zone
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - EXPORT] - ")
+
+		domain := args[0]
+
+		if exportFormat != "bind" {
+			return fmt.Errorf("unsupported format: %s", exportFormat)
+		}
+
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		var zoneFile string
+		if exporter, ok := dnsRepo.UnwrapRepo(repo).(dnsRepo.BindExporter); ok && !exportGeneric {
+			zoneFile, err = exporter.ExportBind(cmd.Context())
+		} else {
+			var records dnsRepo.DnsRecordList
+			records, err = repo.ListRecords(cmd.Context())
+			if err == nil {
+				zoneFile, err = dnsRepo.ExportBind(domain, records, time.Now())
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+
+		if exportOutput == "" {
+			fmt.Fprint(cmd.OutOrStdout(), zoneFile)
+			return nil
+		}
+
+		if err := os.WriteFile(exportOutput, []byte(zoneFile), 0o644); err != nil {
+			return err
+		}
+		cmd.Printf("Exported %s to %s!\n", domain, exportOutput)
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "bind", "The format of the export (bind)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the export to this file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportGeneric, "generic", false, "Build the zone file from the listed records even if the provider has a native export")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var findRecordTypes []string
+
+// findCmd represents the find command
+var findCmd = &cobra.Command{
+	Use:   "find [flags] value",
+	Short: "Search the names and contents of the records of all domains",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can search the records of every domain in the config at once,
+e.g. to find the host names that still point at an old server. An IP address matches the
+same address, a CIDR range every address in it and any other value is searched for in the
+names and contents of the records, ignoring case.
+For example:
+
+  akatran dns find 198.51.100.10
+
+  ----------------------------------------------------------------
+  | ZONE          | TYPE  | NAME          | CONTENT       |  TTL |
+  ----------------------------------------------------------------
+  | example.co.uk | A     | example.co.uk | 198.51.100.10 | 3600 |
+  | example.com   | A     | example.com   | 198.51.100.10 | auto |
+  ----------------------------------------------------------------
+
+  akatran dns find 198.51.100.0/24 --type A
+  akatran dns find old-server.example.net --output json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - FIND] - ")
+
+		value := args[0]
+
+		if jsonOutput {
+			outputFormat = string(dnsRepo.OutputJSON)
+		}
+		out := cmd.OutOrStdout()
+		renderer, err := dnsRepo.NewRenderer(outputFormat, isTerminal(out))
+		if err != nil {
+			return err
+		}
+
+		zones, err := dnsRepo.OpenConfiguredZones(provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		cmd.Printf("Searching %d domains for %s\n", len(zones), value)
+
+		records, listErr := dnsRepo.ListZoneRecords(cmd.Context(), zones, dnsRepo.RecordFilter{Types: findRecordTypes}, concurrency)
+		if records == nil {
+			return listErr
+		}
+		records = utils.Filter(records, func(record dnsRepo.ZoneRecord) bool {
+			return record.MatchesSearch(value)
+		})
+
+		spinner.Stop()
+
+		if err := renderer.RenderZoneRecords(out, records); err != nil {
+			return err
+		}
+		return listErr
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(findCmd)
+
+	findCmd.Flags().StringSliceVarP(&findRecordTypes, "type", "t", nil, "Only search records of the given types (comma separated)")
+	addConcurrencyFlag(findCmd)
+	findCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON, the same as --output json")
+	addOutputFlag(findCmd)
+	findCmd.MarkFlagsMutuallyExclusive("json", "output")
+}
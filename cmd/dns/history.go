@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var historyZone string
+var historyName string
+var historyLimit int
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [flags]",
+	Short: "Show the changes recorded in the audit log",
+	Args:  cobra.NoArgs,
+	Long: `With this command you can browse the audit log of all record changes made with akatran,
+with the state of the record before and after the change, the user, host and command.
+The latest changes are shown, the oldest first. The ID of a change can be passed to undo.
+For example:
+
+  akatran dns history
+  akatran dns history --zone example.com --name www --limit 50
+  akatran dns history --output wide
+  akatran dns history --output jsonl --limit 0
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - HISTORY] - ")
+
+		if jsonOutput {
+			outputFormat = string(dnsRepo.OutputJSON)
+		}
+		out := cmd.OutOrStdout()
+		renderer, err := dnsRepo.NewRenderer(outputFormat, isTerminal(out))
+		if err != nil {
+			return err
+		}
+
+		path, err := auditLogPath(cmd)
+		if err != nil {
+			return err
+		}
+		entries, err := dnsRepo.ReadAuditLog(path)
+		if err != nil {
+			return err
+		}
+
+		entries = utils.Filter(entries, func(entry dnsRepo.AuditEntry) bool {
+			zone := strings.ToLower(strings.TrimSuffix(historyZone, "."))
+			name := strings.ToLower(historyName)
+			return (zone == "" || entry.Zone == zone) &&
+				(name == "" || strings.Contains(strings.ToLower(entry.Record().Name), name))
+		})
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[len(entries)-historyLimit:]
+		}
+
+		return renderer.RenderAuditEntries(out, entries)
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historyZone, "zone", "", "Only show the changes of the zone")
+	historyCmd.Flags().StringVar(&historyName, "name", "", "Only show the changes of records whose name contains the value")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "The number of changes to show, 0 shows all")
+	historyCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON, the same as --output json")
+	addOutputFlag(historyCmd)
+	historyCmd.MarkFlagsMutuallyExclusive("json", "output")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"os"
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var importDryRun bool
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [flags] domain file",
+	Short: "Import the DNS records of a zone file",
+	Args:  cobra.ExactArgs(2),
+	Long: `With this command you can import the records of an RFC 1035 zone file into a domain.
+Records that already exist are left alone, only the missing ones are created.
+SOA records and unsupported record types are skipped.
+For example:
+
+  akatran dns import example.com example.com.zone --dry-run
+  akatran dns import example.com example.com.zone
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - IMPORT] - ")
+
+		domain := strings.ToLower(strings.TrimSuffix(args[0], "."))
+
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		records, skipped, err := dnsRepo.ParseBind(string(data), domain)
+		if err != nil {
+			return err
+		}
+
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		plan, err := dnsRepo.NewImportPlan(cmd.Context(), repo, domain, records)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+
+		for _, skip := range skipped {
+			cmd.Println("Skipping", skip)
+		}
+		cmd.Println(plan)
+
+		if importDryRun || len(plan.Changes) == 0 {
+			return nil
+		}
+
+		spinner.Start()
+		results := plan.Apply(cmd.Context(), repo, concurrency)
+		spinner.Stop()
+		if err := results.Err(); err != nil {
+			cmd.Println()
+			return printResults(cmd, results)
+		}
+
+		cmd.Printf("\nImported %d records into %s!\n", len(plan.Changes), domain)
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(importCmd)
+
+	addConcurrencyFlag(importCmd)
+
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only show the records that would be created")
+}
@@ -23,6 +23,11 @@ package dns
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
 
 	dnsRepo "github.com/akatranlp/akatran/internal/dns"
 	"github.com/akatranlp/akatran/internal/spinner"
@@ -30,39 +35,81 @@ import (
 )
 
 var jsonOutput bool
-var listRecordType string
+var outputFormat string
+var listFilter dnsRepo.RecordFilter
+var listTTL uint32
+var listProxied bool
+var listNotProxied bool
+var listAll bool
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:   "list [flags] domain",
 	Short: "List all DNS records of selected domain",
-	Args:  cobra.ExactArgs(1),
+	Args:  cobra.MaximumNArgs(1),
 	Long: `With the subcommands you can list all records of a given domain.
-You have to provide the domain as an argument. And can choose between table and json output. 
+You have to provide the domain as an argument. The records are printed as table, the
+wide table adds the ID, proxy state, comment and tags. Long contents wrap within the table.
+With --output you can choose between table, wide, json, jsonl, yaml, csv and a Go template
+that is executed for every record. Colors are only used if the output is a terminal.
+The records can be filtered by several types, a name glob or regex, a content substring or
+CIDR range, the TTL, the proxy state and a tag. Providers that support it apply the filters
+in their API, so only the matching records are fetched. With --all the records of every
+domain in the config are listed at the same time, with the zone in the first column.
 For example:
 
-  akatran dns [--token <cloudflare-token>] [--provider <cloudflare>] list example.com 
+  akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] list example.com 
 
-  -------------------------------------------
-  | TYPE  |       NAME      |    CONTENT    |
-  -------------------------------------------
-  | A     |     example.com | 198.51.100.10 |
-  | AAAA  |     example.com |   2001:DB8::1 |
-  | CNAME | www.example.com |   example.com |
-  -------------------------------------------
+  ------------------------------------------------
+  | TYPE  | NAME            | CONTENT     |  TTL |
+  ------------------------------------------------
+  | A     | example.com     | 192.0.2.10  | auto |
+  | AAAA  | example.com     | 2001:db8::1 | auto |
+  | CNAME | www.example.com | example.com |  300 |
+  ------------------------------------------------
 	
 
-  akatran dns list example.com --json
+  akatran dns list example.com --output json
+
+  [{"name":"example.com","type":"A","content":"192.0.2.10"},
+  {"name":"example.com","type":"AAAA","content":"2001:db8::1"},
+  {"name":"www.example.com","type":"CNAME","content":"example.com","ttl":300}]
 
-  [{"name":"example.com","type":"A","content":"192.51.100.10"},
-  {"name":"example.com","type":"AAAA","content":"2001:DB8::1"},
-  {"name":"www.example.com","type":"CNAME","content":"example.com"}]
+  akatran dns list example.com --output wide
+  akatran dns list example.com --output csv > records.csv
+  akatran dns list example.com --output 'template={{.Name}} {{.Type}} {{.Content}}'
+  akatran dns list example.com --type A,AAAA --name '*.dev.example.com'
+  akatran dns list example.com --content 10.0.0.0/8 --max-ttl 300 --no-proxied
+  akatran dns list --all --type A,AAAA
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SetErrPrefix("Error: [DNS - LIST] - ")
 
-		domain := args[0]
+		if (len(args) == 0) != listAll {
+			return fmt.Errorf("either a domain or --all has to be provided")
+		}
+
+		if cmd.Flags().Changed("ttl") {
+			listFilter.MinTTL, listFilter.MaxTTL = listTTL, listTTL
+		}
+		if listProxied || listNotProxied {
+			listFilter.Proxied = &listProxied
+		}
+
+		if jsonOutput {
+			outputFormat = string(dnsRepo.OutputJSON)
+		}
+		out := cmd.OutOrStdout()
+		renderer, err := dnsRepo.NewRenderer(outputFormat, isTerminal(out))
+		if err != nil {
+			return err
+		}
+
+		if listAll {
+			return listAllZones(cmd, renderer)
+		}
 
+		domain := args[0]
 		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
 		if err != nil {
 			return err
@@ -73,40 +120,75 @@ For example:
 
 		cmd.Println("Listing DNS records for", domain)
 
-		var dnsRecords dnsRepo.DnsRecordList
-		switch listRecordType {
-		case "":
-			dnsRecords, err = repo.ListRecords(cmd.Context())
-			if err != nil {
-				return err
-			}
-		case "A":
-			fallthrough
-		case "AAAA":
-			fallthrough
-		case "CNAME":
-			dnsRecords, err = repo.ListRecords(cmd.Context(), listRecordType)
-			if err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("invalid record type")
+		dnsRecords, err := dnsRepo.FilterRecords(cmd.Context(), repo, listFilter)
+		if err != nil {
+			return err
 		}
 
 		spinner.Stop()
 
-		if jsonOutput {
-			cmd.Println(dnsRecords.AsJsonString())
-		} else {
-			cmd.Println(dnsRecords.AsTableString())
-		}
-		return nil
+		return renderer.RenderRecords(out, dnsRecords)
 	},
 }
 
+// listAllZones lists the records of every configured domain. The records of the domains
+// that could be listed are printed even if others failed.
+func listAllZones(cmd *cobra.Command, renderer *dnsRepo.Renderer) error {
+	zones, err := dnsRepo.OpenConfiguredZones(provider, token)
+	if err != nil {
+		return err
+	}
+
+	spinner.Start()
+	defer spinner.Stop()
+
+	cmd.Printf("Listing DNS records for %d domains\n", len(zones))
+
+	records, listErr := dnsRepo.ListZoneRecords(cmd.Context(), zones, listFilter, concurrency)
+	if records == nil {
+		return listErr
+	}
+
+	spinner.Stop()
+
+	if err := renderer.RenderZoneRecords(cmd.OutOrStdout(), records); err != nil {
+		return err
+	}
+	return listErr
+}
+
+// isTerminal reports whether w is a terminal and colors are not disabled by NO_COLOR.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
+
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", string(dnsRepo.OutputTable), fmt.Sprintf("The output format (%s)", strings.Join(dnsRepo.OutputFormats, ", ")))
+}
+
 func init() {
 	DnsCmd.AddCommand(listCmd)
 
-	listCmd.Flags().StringVarP(&listRecordType, "type", "t", "", "The type of the DNS record")
-	listCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+	listCmd.Flags().StringSliceVarP(&listFilter.Types, "type", "t", nil, "Only list records of the given types (comma separated)")
+	listCmd.Flags().StringVar(&listFilter.Name, "name", "", "Only list records whose name matches the glob, e.g. '*.dev.example.com'")
+	listCmd.Flags().StringVar(&listFilter.NameRegex, "name-regex", "", "Only list records whose name matches the regular expression")
+	listCmd.Flags().StringVarP(&listFilter.Content, "content", "c", "", "Only list records whose content contains the value or lies in the CIDR range")
+	listCmd.Flags().Uint32Var(&listTTL, "ttl", 0, "Only list records with the given TTL")
+	listCmd.Flags().Uint32Var(&listFilter.MinTTL, "min-ttl", 0, "Only list records with at least the given TTL")
+	listCmd.Flags().Uint32Var(&listFilter.MaxTTL, "max-ttl", 0, "Only list records with at most the given TTL")
+	listCmd.Flags().BoolVar(&listProxied, "proxied", false, "Only list proxied records")
+	listCmd.Flags().BoolVar(&listNotProxied, "no-proxied", false, "Only list records that are not proxied")
+	listCmd.Flags().StringVar(&listFilter.Tag, "tag", "", "Only list records with the given tag (name or name:value)")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "List the records of every domain in the config")
+	addConcurrencyFlag(listCmd)
+	listCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON, the same as --output json")
+	addOutputFlag(listCmd)
+	listCmd.MarkFlagsMutuallyExclusive("json", "output")
+	listCmd.MarkFlagsMutuallyExclusive("proxied", "no-proxied")
+	listCmd.MarkFlagsMutuallyExclusive("ttl", "min-ttl")
+	listCmd.MarkFlagsMutuallyExclusive("ttl", "max-ttl")
 }
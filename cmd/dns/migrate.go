@@ -0,0 +1,156 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var migratePrune bool
+var migrateYes bool
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [flags] domain",
+	Short: "Copy the DNS records of a domain to another provider",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can copy the records of a domain from one provider to
+another. Missing records are created and changed records are updated at the target.
+Records that only exist at the target are kept unless --prune is set. The NS records
+of the domain itself are neither copied nor pruned, as they delegate the zone to the
+name servers of each provider, unless --include-ns is set.
+Afterwards both sides are compared again to verify the migration.
+The providers are selected by profiles in the config file, see diff.
+For example:
+
+  akatran dns migrate example.com --from old --to new
+  akatran dns migrate example.com --from old --to new --prune --yes
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - MIGRATE] - ")
+
+		domain := args[0]
+
+		from, to, err := profileRepos(domain)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		diffs, err := dnsRepo.VerifyMigration(cmd.Context(), domain, from, to, migratePrune, diffIncludeNS)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+
+		if len(diffs) == 0 {
+			return printMigration(cmd, domain, nil, nil)
+		}
+
+		if !jsonOutput {
+			cmd.Print(diffs.AsTableString())
+			if !migrateYes && !confirm(cmd, fmt.Sprintf("Do you want to migrate %d records?", len(diffs))) {
+				cmd.Println("Migration cancelled.")
+				return nil
+			}
+		} else if !migrateYes {
+			return fmt.Errorf("--yes is required with --json")
+		}
+
+		spinner.Start()
+		results := dnsRepo.Migrate(cmd.Context(), to, diffs, migratePrune, concurrency)
+
+		remaining, err := dnsRepo.VerifyMigration(cmd.Context(), domain, from, to, migratePrune, diffIncludeNS)
+		if err != nil {
+			return err
+		}
+		spinner.Stop()
+
+		if err := printMigration(cmd, domain, results, remaining); err != nil {
+			return err
+		}
+		if err := results.Err(); err != nil {
+			return err
+		}
+		if len(remaining) > 0 {
+			return fmt.Errorf("verification failed, %d records still differ", len(remaining))
+		}
+		return nil
+	},
+}
+
+func printMigration(cmd *cobra.Command, domain string, migrated dnsRepo.RecordResultList, remaining dnsRepo.RecordDiffList) error {
+	if jsonOutput {
+		if migrated == nil {
+			migrated = dnsRepo.RecordResultList{}
+		}
+		if remaining == nil {
+			remaining = dnsRepo.RecordDiffList{}
+		}
+		data, err := json.Marshal(map[string]any{
+			"domain":    domain,
+			"migrated":  migrated,
+			"remaining": remaining,
+			"verified":  len(remaining) == 0,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(migrated) == 0 {
+		cmd.Printf("The records of %s already match, nothing to migrate!\n", domain)
+		return nil
+	}
+	if migrated.Err() != nil {
+		cmd.Println()
+		cmd.Print(migrated.AsTableString())
+		cmd.Println(migrated.Summary())
+	}
+	if len(remaining) > 0 {
+		cmd.Println("\nThe following records still differ after the migration:")
+		cmd.Print(remaining.AsTableString())
+		return nil
+	}
+	cmd.Printf("\nMigrated %d records of %s and verified that both sides match!\n", len(migrated), domain)
+	return nil
+}
+
+func init() {
+	DnsCmd.AddCommand(migrateCmd)
+
+	addProfileFlags(migrateCmd)
+	addConcurrencyFlag(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migratePrune, "prune", false, "Delete records that only exist at the target")
+	migrateCmd.Flags().BoolVarP(&migrateYes, "yes", "y", false, "Migrate without asking for confirmation")
+	migrateCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+}
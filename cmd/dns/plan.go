@@ -0,0 +1,105 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var planOut string
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [flags] file",
+	Short: "Show the changes needed to make a zone match a YAML file",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can compare the records of a zone with the records
+declared in a YAML file. The plan lists the records that would be created, updated
+and deleted by apply and can be saved to be applied later.
+
+Only records managed by akatran are updated or deleted. With the default ownership
+"marker" these are the records whose comment starts with the marker, which apply
+adds to every record it writes. Providers without comments, like hetzner and rfc2136,
+need "ownership: all" to delete records, which manages every record of the zone.
+
+  zone: example.com
+  ownership: marker            # or all
+  marker: managed by akatran   # default
+  records:
+    - name: "@"
+      type: A
+      content: 198.51.100.10
+    - name: www
+      type: CNAME
+      content: example.com
+      ttl: 300
+    - name: "@"
+      type: MX
+      content: mail.example.com
+      priority: 10
+
+For example:
+
+  akatran dns plan example.com.yaml
+  akatran dns plan example.com.yaml --out example.com.plan
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - PLAN] - ")
+
+		spec, err := dnsRepo.LoadZoneSpec(args[0])
+		if err != nil {
+			return err
+		}
+
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(spec.Zone, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		plan, err := dnsRepo.NewPlan(cmd.Context(), repo, spec)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		cmd.Println(plan)
+
+		if planOut != "" {
+			if err := plan.Save(planOut); err != nil {
+				return err
+			}
+			cmd.Printf("\nSaved the plan to %s, apply it with: akatran dns apply --plan %s\n", planOut, planOut)
+		}
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVarP(&planOut, "out", "o", "", "Save the plan to this file")
+}
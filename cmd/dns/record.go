@@ -0,0 +1,192 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var recordType string
+var recordContent string
+var recordPriority uint16
+var recordWeight uint16
+var recordPort uint16
+var recordFlags uint8
+var recordTag string
+var recordTTL uint32
+var recordProxied bool
+var recordNoProxied bool
+var recordComment string
+var recordTags []string
+var recordID string
+var recordAll bool
+
+func addRecordFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&recordType, "type", "t", "A", fmt.Sprintf("The type of the DNS record (%s)", strings.Join(dnsRepo.SupportedRecordTypes, ", ")))
+	cmd.Flags().StringVarP(&recordContent, "content", "c", "", "The content of the DNS record")
+	cmd.Flags().Uint16Var(&recordPriority, "priority", 0, "The priority of MX and SRV records")
+	cmd.Flags().Uint16Var(&recordWeight, "weight", 0, "The weight of SRV records")
+	cmd.Flags().Uint16Var(&recordPort, "port", 0, "The port of SRV records")
+	cmd.Flags().Uint8Var(&recordFlags, "flags", 0, "The flags of CAA records")
+	cmd.Flags().StringVar(&recordTag, "tag", "", "The tag of CAA records (issue, issuewild, iodef), new records default to issue")
+	cmd.Flags().Uint32Var(&recordTTL, "ttl", 0, "The TTL of the DNS record in seconds (default is the provider default)")
+	cmd.Flags().BoolVar(&recordProxied, "proxied", false, "Proxy the DNS record through the provider")
+	cmd.Flags().BoolVar(&recordNoProxied, "no-proxied", false, "Do not proxy the DNS record through the provider")
+	cmd.Flags().StringVar(&recordComment, "comment", "", "A comment for the DNS record")
+	cmd.Flags().StringSliceVar(&recordTags, "tags", nil, "Tags of the DNS record, e.g. env:prod,team")
+
+	cmd.MarkFlagsMutuallyExclusive("proxied", "no-proxied")
+}
+
+// addSelectFlags adds the flags that select which of several records with the same name and type are changed.
+func addSelectFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&recordID, "id", "", "The ID of the record to change if several records share name and type")
+	cmd.Flags().BoolVar(&recordAll, "all", false, "Change all records with the name and type")
+
+	cmd.MarkFlagsMutuallyExclusive("id", "all")
+}
+
+// printSetResults prints what happened to each record of a set or update.
+func printSetResults(cmd *cobra.Command, results dnsRepo.SetResultList) {
+	for _, result := range results {
+		if result.Action == dnsRepo.SetUnchanged {
+			cmd.Printf("DNS record %s (%s %s) is unchanged.\n", result.Record.Name, result.Record.Type, result.Record.Content)
+			continue
+		}
+		cmd.Printf("DNS record %s (%s %s) %s!\n", result.Record.Name, result.Record.Type, result.Record.Content, result.Action)
+	}
+}
+
+// ambiguousHint adds the flags to resolve an ambiguous record to the error.
+func ambiguousHint(err error) error {
+	if errors.Is(err, dnsRepo.ErrAmbiguousRecord) {
+		return fmt.Errorf("%w\nselect a record with --id or change all of them with --all", err)
+	}
+	return err
+}
+
+// buildRecord creates the record from the flags. A and AAAA records without
+// content use the public IP address of this machine. Attributes whose flags were
+// not set stay unset, so an update keeps their current values, while the ones that
+// were set are applied even if they are empty, e.g. --priority=0 or --comment="".
+func buildRecord(cmd *cobra.Command, name string) (dnsRepo.DnsRecord, error) {
+	record := dnsRepo.DnsRecord{
+		ID:      recordID,
+		Name:    name,
+		Type:    recordType,
+		Content: recordContent,
+		TTL:     recordTTL,
+		Comment: recordComment,
+	}
+
+	switch {
+	case cmd.Flags().Changed("proxied"):
+		record.Proxied = &recordProxied
+	case cmd.Flags().Changed("no-proxied"):
+		proxied := !recordNoProxied
+		record.Proxied = &proxied
+	}
+	if cmd.Flags().Changed("ttl") {
+		record = record.WithFields(dnsRepo.FieldTTL)
+	}
+	if cmd.Flags().Changed("comment") {
+		record = record.WithFields(dnsRepo.FieldComment)
+	}
+	if cmd.Flags().Changed("tags") {
+		record.Tags = recordTags
+		record = record.WithFields(dnsRepo.FieldTags)
+	}
+
+	switch recordType {
+	case "A":
+		ip, err := utils.GetIPv4Address(cmd.Context(), recordContent)
+		if err != nil {
+			return record, err
+		}
+		record.Content = ip.String()
+	case "AAAA":
+		ip, err := utils.GetIPv6Address(cmd.Context(), recordContent)
+		if err != nil {
+			return record, err
+		}
+		record.Content = ip.String()
+	case "CNAME":
+		if recordContent == "" {
+			return record, fmt.Errorf("content is required for CNAME records")
+		}
+		if strings.Contains(recordContent, "://") {
+			target, err := url.Parse(recordContent)
+			if err != nil {
+				return record, err
+			}
+			record.Content = target.Hostname()
+		}
+	}
+
+	if slices.Contains([]string{"MX", "SRV"}, recordType) && cmd.Flags().Changed("priority") {
+		record.Priority = recordPriority
+		record = record.WithFields(dnsRepo.FieldPriority)
+	}
+	if recordType == "SRV" {
+		if cmd.Flags().Changed("weight") {
+			record.Weight = recordWeight
+			record = record.WithFields(dnsRepo.FieldWeight)
+		}
+		if cmd.Flags().Changed("port") {
+			record.Port = recordPort
+			record = record.WithFields(dnsRepo.FieldPort)
+		}
+	}
+	if recordType == "CAA" {
+		if cmd.Flags().Changed("flags") {
+			record.Flags = recordFlags
+			record = record.WithFields(dnsRepo.FieldFlags)
+		}
+		if cmd.Flags().Changed("tag") {
+			record.Tag = recordTag
+		}
+	}
+
+	if record.Content == "" {
+		return record, fmt.Errorf("content is required for %s records", recordType)
+	}
+
+	return dnsRepo.NormalizeUpdate(record)
+}
+
+// buildNewRecord creates a record that does not exist yet from the flags and sets the
+// defaults of the attributes whose flags were not set.
+func buildNewRecord(cmd *cobra.Command, name string) (dnsRepo.DnsRecord, error) {
+	record, err := buildRecord(cmd, name)
+	if err != nil {
+		return record, err
+	}
+	return dnsRepo.CompleteRecord(record)
+}
@@ -0,0 +1,86 @@
+package dns
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/spf13/cobra"
+)
+
+func TestBuildRecordUpdate(t *testing.T) {
+	existing := []dnsRepo.DnsRecord{
+		{Name: "example.com", Type: "MX", Content: "mx.example.com", Priority: 10, TTL: 300, Comment: "mail"},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060},
+		{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128, Tag: "issue"},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want dnsRepo.DnsRecord
+	}{
+		{
+			name: "unset flags keep the values",
+			args: []string{"--type", "MX", "--content", "mx2.example.com"},
+			want: dnsRepo.DnsRecord{Name: "example.com", Type: "MX", Content: "mx2.example.com", Priority: 10, TTL: 300, Comment: "mail"},
+		},
+		{
+			name: "priority, ttl and comment set to zero",
+			args: []string{"--type", "MX", "--content", "mx.example.com", "--priority", "0", "--ttl", "0", "--comment", ""},
+			want: dnsRepo.DnsRecord{Name: "example.com", Type: "MX", Content: "mx.example.com"},
+		},
+		{
+			name: "srv weight and port set to zero",
+			args: []string{"--type", "SRV", "--content", "sip.example.com", "--weight", "0", "--port", "0"},
+			want: dnsRepo.DnsRecord{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10},
+		},
+		{
+			name: "caa flags set to zero",
+			args: []string{"--type", "CAA", "--content", "letsencrypt.org", "--flags", "0"},
+			want: dnsRepo.DnsRecord{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Tag: "issue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, err := dnsRepo.NewMemoryRepo("example.com", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, record := range existing {
+				if err := repo.CreateRecord(ctx, record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := &cobra.Command{}
+			cmd.SetContext(ctx)
+			addRecordFlags(cmd)
+			addSelectFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+
+			record, err := buildRecord(cmd, tt.want.Name)
+			if err != nil {
+				t.Fatalf("buildRecord() error = %v", err)
+			}
+			if _, err := dnsRepo.UpdateRecords(ctx, repo, record, false); err != nil {
+				t.Fatalf("UpdateRecords() error = %v", err)
+			}
+
+			records, err := dnsRepo.FindRecords(ctx, repo, dnsRepo.DnsRecord{Name: tt.want.Name, Type: tt.want.Type})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := records[0]
+			got.ID = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("record after the update = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
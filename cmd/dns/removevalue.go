@@ -0,0 +1,98 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+// removeValueCmd represents the remove-value command
+var removeValueCmd = &cobra.Command{
+	Use:   "remove-value [flags] dns_record",
+	Short: "Remove a value from the records with the same name and type",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can remove a single value from a record set like round-robin A records
+or several TXT records. The other values with the same name and type are kept.
+Nothing is changed if the value does not exist.
+For example:
+
+  akatran dns remove-value api.example.com --content 198.51.100.13
+  akatran dns remove-value example.com --type TXT --content "google-site-verification=abc"
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - REMOVE-VALUE] - ")
+
+		dnsRecord := args[0]
+
+		if err := dnsRepo.ValidateRecordTypes(recordType); err != nil {
+			return err
+		}
+
+		_, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		deleted, remaining, err := dnsRepo.RemoveValue(cmd.Context(), repo, dnsRepo.DnsRecord{
+			Name:    dnsRecord,
+			Type:    recordType,
+			Content: recordContent,
+		})
+		spinner.Stop()
+		if len(deleted) == 0 && err == nil {
+			cmd.Printf("The %s record %s does not have the value %s!\n", recordType, dnsRecord, recordContent)
+			return nil
+		}
+		if len(deleted) > 0 {
+			cmd.Printf("Value %s removed from the %s record %s, %s\n", recordContent, recordType, dnsRecord, remainingValues(remaining))
+		}
+		return err
+	},
+}
+
+func remainingValues(remaining dnsRepo.DnsRecordList) string {
+	if len(remaining) == 0 {
+		return "no values are left!"
+	}
+	values := make([]string, 0, len(remaining))
+	for _, record := range remaining {
+		values = append(values, record.Content)
+	}
+	return fmt.Sprintf("remaining values: %s", strings.Join(values, ", "))
+}
+
+func init() {
+	DnsCmd.AddCommand(removeValueCmd)
+
+	addZoneFlag(removeValueCmd)
+	removeValueCmd.Flags().StringVarP(&recordType, "type", "t", "A", fmt.Sprintf("The type of the DNS record (%s)", strings.Join(dnsRepo.SupportedRecordTypes, ", ")))
+	removeValueCmd.Flags().StringVarP(&recordContent, "content", "c", "", "The value to remove")
+	removeValueCmd.MarkFlagRequired("content")
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var replaceRecordTypes []string
+var replaceYes bool
+
+// replaceContentCmd represents the replace-content command
+var replaceContentCmd = &cobra.Command{
+	Use:   "replace-content [flags] old new",
+	Short: "Replace a content in the records of all domains",
+	Args:  cobra.ExactArgs(2),
+	Long: `With this command you can replace the content of every record of every domain in the
+config that has exactly the old content, e.g. to move all host names to a new server.
+All changes are shown before anything is changed and have to be confirmed.
+For example:
+
+  akatran dns replace-content 198.51.100.10 203.0.113.20
+  akatran dns replace-content old-server.example.net new-server.example.net --type CNAME
+  akatran dns replace-content 198.51.100.10 203.0.113.20 --yes
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - REPLACE-CONTENT] - ")
+
+		old, new := args[0], args[1]
+
+		zones, err := dnsRepo.OpenConfiguredZones(provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		records, err := dnsRepo.ListZoneRecords(cmd.Context(), zones, dnsRepo.RecordFilter{Types: replaceRecordTypes, Content: strings.TrimSuffix(old, ".")}, concurrency)
+		if err != nil {
+			return err
+		}
+
+		replacements, err := dnsRepo.PlanContentReplacement(records, old, new)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+
+		if len(replacements) == 0 {
+			cmd.Printf("No records with the content %s found in %d domains!\n", old, len(zones))
+			return nil
+		}
+
+		cmd.Print(replacements.AsTableString())
+		if !replaceYes && !confirm(cmd, fmt.Sprintf("\nDo you want to replace the content of these %d records?", len(replacements))) {
+			cmd.Println("Replace cancelled.")
+			return nil
+		}
+
+		spinner.Start()
+		results := dnsRepo.ReplaceContent(cmd.Context(), zones, replacements, concurrency)
+		spinner.Stop()
+
+		cmd.Println()
+		return printResults(cmd, results)
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(replaceContentCmd)
+
+	replaceContentCmd.Flags().StringSliceVarP(&replaceRecordTypes, "type", "t", nil, "Only replace the content of records of the given types (comma separated)")
+	replaceContentCmd.Flags().BoolVarP(&replaceYes, "yes", "y", false, "Replace the content without asking for confirmation")
+	replaceContentCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the result of every record as JSON")
+	addConcurrencyFlag(replaceContentCmd)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var restoreYes bool
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore [flags] domain id|latest",
+	Short: "Bring the DNS records of a domain back to a snapshot",
+	Args:  cobra.ExactArgs(2),
+	Long: `With this command you can roll a domain back to a snapshot taken with snapshot.
+Only the records that differ from the snapshot are created, updated or deleted, records
+created after the snapshot are deleted. The changes are shown and have to be confirmed.
+Before anything is changed a snapshot of the current records is taken, so the restore
+can be reverted as well.
+For example:
+
+  akatran dns restore example.com latest
+  akatran dns restore example.com 20240501T101500Z --yes
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - RESTORE] - ")
+
+		domain := zoneArg(args[0])
+
+		store, err := snapshotStore(cmd)
+		if err != nil {
+			return err
+		}
+		snapshot, err := store.Load(domain, args[1])
+		if err != nil {
+			return err
+		}
+
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		plan, err := dnsRepo.NewRestorePlan(cmd.Context(), repo, snapshot)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		cmd.Println(plan)
+
+		if len(plan.Changes) == 0 {
+			return nil
+		}
+
+		if !restoreYes && !confirm(cmd, fmt.Sprintf("\nDo you want to restore %s to snapshot %s?", domain, snapshot.ID)) {
+			cmd.Println("Restore cancelled.")
+			return nil
+		}
+
+		spinner.Start()
+		current, err := store.Take(cmd.Context(), repo, domain)
+		if err != nil {
+			return fmt.Errorf("failed to take a snapshot before restoring: %w", err)
+		}
+		results := plan.Apply(cmd.Context(), repo, concurrency)
+		spinner.Stop()
+
+		cmd.Printf("\nSnapshot %s of the records before the restore saved.\n", current.ID)
+		if err := results.Err(); err != nil {
+			cmd.Println()
+			return printResults(cmd, results)
+		}
+
+		cmd.Printf("Restored %s to snapshot %s with %d changes!\n", domain, snapshot.ID, len(plan.Changes))
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(restoreCmd)
+
+	addConcurrencyFlag(restoreCmd)
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Restore the snapshot without asking for confirmation")
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set [flags] dns_record",
+	Short: "Create a DNS record or update it if it exists",
+	Args:  cobra.ExactArgs(1),
+	Long: `With the subcommands you can make sure the record exists with the given content.
+A missing record is created, an existing one is updated and a record that already matches is left unchanged.
+if you don't provide the content flag, your public IP-Adress from the record type will be used. 
+If several records share the name and type, the one with the same content is updated.
+Otherwise select one with --id or update all of them with --all.
+For example:
+
+  akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] set www.example.com [--content content] [--type A|AAAA|CNAME|MX|TXT|NS|SRV|CAA|PTR]
+  akatran dns set www.example.com
+  akatran dns set www.example.com --type CNAME --content example.com --ttl 300
+  akatran dns set api.example.com --id 3f2a9c --content 198.51.100.20
+  akatran dns set host.lab.example.com --zone lab.example.com --content 192.0.2.1 --wait
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - SET] - ")
+
+		dnsRecord := args[0]
+
+		recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
+		if err != nil {
+			return err
+		}
+
+		record, err := buildRecord(cmd, dnsRecord)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		results, err := dnsRepo.SetRecord(cmd.Context(), repo, record, recordAll)
+		spinner.Stop()
+		printSetResults(cmd, results)
+		if err != nil {
+			return ambiguousHint(err)
+		}
+
+		if err := waitForPropagation(cmd, recordZone, updatedRecord(record, results)); err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(setCmd)
+
+	addZoneFlag(setCmd)
+	addWaitFlag(setCmd)
+	addRecordFlags(setCmd)
+	addSelectFlags(setCmd)
+}
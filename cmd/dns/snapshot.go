@@ -0,0 +1,231 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/spf13/cobra"
+)
+
+// snapshotStore returns the store in snapshot::dir or the snapshots directory in the state directory.
+func snapshotStore(cmd *cobra.Command) (*dnsRepo.SnapshotStore, error) {
+	if dir := viper.GetString("snapshot::dir"); dir != "" {
+		return dnsRepo.NewSnapshotStore(dir), nil
+	}
+
+	stateDir, err := utils.StateDir(cmd.Root().Name())
+	if err != nil {
+		return nil, err
+	}
+	return dnsRepo.NewSnapshotStore(filepath.Join(stateDir, "snapshots")), nil
+}
+
+func zoneArg(arg string) string {
+	return strings.ToLower(strings.TrimSuffix(arg, "."))
+}
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [flags] domain",
+	Short: "Save a copy of all DNS records of a domain",
+	Args:  cobra.ExactArgs(1),
+	Long: `With this command you can save a copy of all records of a domain before risky changes.
+The snapshots are kept in $XDG_STATE_HOME/akatran/snapshots, snapshot::dir in the config file
+changes the directory. Every snapshot has the time it was taken as ID, latest selects the
+latest snapshot of a domain. With restore the domain can be brought back to a snapshot.
+For example:
+
+  akatran dns snapshot example.com
+  akatran dns snapshot list
+  akatran dns snapshot show example.com latest
+  akatran dns snapshot diff example.com 20240501T101500Z
+  akatran dns restore example.com 20240501T101500Z
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - SNAPSHOT] - ")
+
+		domain := zoneArg(args[0])
+
+		store, err := snapshotStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		if err != nil {
+			return err
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		snapshot, err := store.Take(cmd.Context(), repo, domain)
+		if err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		cmd.Printf("Snapshot %s of %s with %d records saved!\n", snapshot.ID, domain, len(snapshot.Records))
+		return nil
+	},
+}
+
+// snapshotListCmd represents the snapshot list command
+var snapshotListCmd = &cobra.Command{
+	Use:   "list [flags] [domain]",
+	Short: "List the snapshots of all or one domain",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - SNAPSHOT] - ")
+
+		domain := ""
+		if len(args) > 0 {
+			domain = zoneArg(args[0])
+		}
+
+		out := cmd.OutOrStdout()
+		renderer, err := dnsRepo.NewRenderer(outputFormat, isTerminal(out))
+		if err != nil {
+			return err
+		}
+
+		store, err := snapshotStore(cmd)
+		if err != nil {
+			return err
+		}
+		snapshots, err := store.List(domain)
+		if err != nil {
+			return err
+		}
+
+		return renderer.RenderSnapshots(out, snapshots)
+	},
+}
+
+// snapshotShowCmd represents the snapshot show command
+var snapshotShowCmd = &cobra.Command{
+	Use:   "show [flags] domain id|latest",
+	Short: "Show the records of a snapshot",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - SNAPSHOT] - ")
+
+		out := cmd.OutOrStdout()
+		renderer, err := dnsRepo.NewRenderer(outputFormat, isTerminal(out))
+		if err != nil {
+			return err
+		}
+
+		store, err := snapshotStore(cmd)
+		if err != nil {
+			return err
+		}
+		snapshot, err := store.Load(zoneArg(args[0]), args[1])
+		if err != nil {
+			return err
+		}
+
+		return renderer.RenderRecords(out, snapshot.Records)
+	},
+}
+
+// snapshotDiffCmd represents the snapshot diff command
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff [flags] domain id|latest [id|latest]",
+	Short: "Compare a snapshot with the current records or another snapshot",
+	Args:  cobra.RangeArgs(2, 3),
+	Long: `With this command you can see what changed since a snapshot was taken. The snapshot is
+compared with the current records of the domain, or with a second snapshot. Records are
+reported as missing if they only exist in the snapshot, as extra if they only exist in the
+current records or the second snapshot and as changed if their TTL or proxy state differs.
+For example:
+
+  akatran dns snapshot diff example.com latest
+  akatran dns snapshot diff example.com 20240501T101500Z 20240601T090000Z
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - SNAPSHOT] - ")
+
+		domain := zoneArg(args[0])
+
+		store, err := snapshotStore(cmd)
+		if err != nil {
+			return err
+		}
+		from, err := store.Load(domain, args[1])
+		if err != nil {
+			return err
+		}
+
+		var to dnsRepo.DnsRecordList
+		if len(args) == 3 {
+			snapshot, err := store.Load(domain, args[2])
+			if err != nil {
+				return err
+			}
+			to = snapshot.Records
+		} else {
+			repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+			if err != nil {
+				return err
+			}
+
+			spinner.Start()
+			defer spinner.Stop()
+
+			to, err = repo.ListRecords(cmd.Context())
+			if err != nil {
+				return err
+			}
+			spinner.Stop()
+		}
+
+		diffs := dnsRepo.DiffRecords(from.Records, to)
+
+		if jsonOutput {
+			fmt.Fprint(cmd.OutOrStdout(), diffs.AsJsonString())
+			return nil
+		}
+		if len(diffs) == 0 {
+			cmd.Printf("The records of %s match snapshot %s!\n", domain, from.ID)
+			return nil
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diffs.AsTableString())
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd, snapshotShowCmd, snapshotDiffCmd)
+
+	addOutputFlag(snapshotListCmd)
+	addOutputFlag(snapshotShowCmd)
+	snapshotDiffCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+}
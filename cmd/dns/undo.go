@@ -0,0 +1,104 @@
+/*
+Copyright © 2024 Fabian Petersen <fabian@nf-petersen.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dns
+
+import (
+	"fmt"
+	"time"
+
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
+	"github.com/akatranlp/akatran/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var undoYes bool
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo [flags] [id]",
+	Short: "Revert a change recorded in the audit log",
+	Args:  cobra.MaximumNArgs(1),
+	Long: `With this command you can revert a change from the audit log, by default the latest one.
+A created record is deleted, an updated record gets its previous state back and a deleted
+record is created again. The change is only reverted if the record was not changed since.
+The revert itself is recorded in the audit log as well.
+For example:
+
+  akatran dns undo
+  akatran dns undo 3f2a9c1e
+  akatran dns undo 3f2a9c1e --yes
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetErrPrefix("Error: [DNS - UNDO] - ")
+
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+
+		path, err := auditLogPath(cmd)
+		if err != nil {
+			return err
+		}
+		entries, err := dnsRepo.ReadAuditLog(path)
+		if err != nil {
+			return err
+		}
+		entry, err := entries.Find(id)
+		if err != nil {
+			return err
+		}
+
+		// Zones that are not configured use the provider the change was made with.
+		undoProvider := provider
+		if undoProvider == "" {
+			undoProvider = entry.Provider
+		}
+		repo, err := dnsRepo.GetRepoFromViperOrFlag(entry.Zone, undoProvider, token)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Change %s by %s on %s at %s: %s\n", entry.ID, entry.User, entry.Host, entry.Time.Local().Format(time.DateTime), entry.Command)
+		if !undoYes && !confirm(cmd, fmt.Sprintf("Do you want to %s?", entry.Undo())) {
+			cmd.Println("Undo cancelled.")
+			return nil
+		}
+
+		spinner.Start()
+		defer spinner.Stop()
+
+		if err := dnsRepo.UndoEntry(cmd.Context(), repo, entry); err != nil {
+			return err
+		}
+
+		spinner.Stop()
+		cmd.Printf("Change %s reverted!\n", entry.ID)
+		return nil
+	},
+}
+
+func init() {
+	DnsCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVarP(&undoYes, "yes", "y", false, "Revert the change without asking for confirmation")
+}
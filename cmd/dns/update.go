@@ -22,13 +22,8 @@ THE SOFTWARE.
 package dns
 
 import (
-	"fmt"
-	"net/url"
-	"strings"
-
 	dnsRepo "github.com/akatranlp/akatran/internal/dns"
 	"github.com/akatranlp/akatran/internal/spinner"
-	"github.com/akatranlp/akatran/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -39,22 +34,24 @@ var updateCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Long: `With the subcommands you can update the given record.
 if you don't provide the content flag, your public IP-Adress from the record type will be used. 
+TTL, proxy state, comment and tags are only changed if their flags are set.
+If several records share the name and type, select one with --id or update all of them with --all.
 For example:
 
-  akatran dns [--token <cloudflare-token>] [--provider <cloudflare>] update www.example.com [--content content]
+  akatran dns [--token <api-token>] [--provider <cloudflare|hetzner|rfc2136|zonefile|memory>] update www.example.com [--content content] [--type A|AAAA|CNAME|MX|TXT|NS|SRV|CAA|PTR]
   akatran dns update www.example.com 
+  akatran dns update example.com --type MX --content mail2.example.com --priority 20
+  akatran dns update www.example.com --no-proxied --ttl 3600
+  akatran dns update host.lab.example.com --zone lab.example.com --content 192.0.2.2
+  akatran dns update api.example.com --id 3f2a9c --content 198.51.100.20
+  akatran dns update api.example.com --all --ttl 300 --content 198.51.100.20
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dnsRecord := args[0]
-
-		parts := strings.Split(dnsRecord, ".")
-		if len(parts) < 2 {
-			return fmt.Errorf("invalid dns record")
-		}
+		cmd.SetErrPrefix("Error: [DNS - UPDATE] - ")
 
-		domain := strings.Join(parts[len(parts)-2:], ".")
+		dnsRecord := args[0]
 
-		repo, err := dnsRepo.GetRepoFromViperOrFlag(domain, provider, token)
+		recordZone, repo, err := dnsRepo.ResolveZone(cmd.Context(), dnsRecord, zone, provider, token)
 		if err != nil {
 			return err
 		}
@@ -62,40 +59,21 @@ For example:
 		spinner.Start()
 		defer spinner.Stop()
 
-		switch recordType {
-		case "A":
-			ip := utils.GetIPv4Address(recordContent)
-			if ip == nil {
-				return fmt.Errorf("invalid IPv4 address")
-			}
-			recordContent = ip.String()
-		case "AAAA":
-			ip := utils.GetIPv6Address(recordContent)
-			if ip == nil {
-				return fmt.Errorf("invalid IPv6 address")
-			}
-			recordContent = ip.String()
-		case "CNAME":
-			if recordContent == "" {
-				return fmt.Errorf("content is required for CNAME records")
-			}
-			domain, err := url.Parse(recordContent)
-			if err != nil {
-				return err
-			}
-			recordContent = domain.Hostname()
-		}
-
-		if err := repo.UpdateRecord(cmd.Context(), dnsRepo.DnsRecord{
-			Name:    dnsRecord,
-			Type:    recordType,
-			Content: recordContent,
-		}); err != nil {
+		record, err := buildRecord(cmd, dnsRecord)
+		if err != nil {
 			return err
 		}
 
+		results, err := dnsRepo.UpdateRecords(cmd.Context(), repo, record, recordAll)
 		spinner.Stop()
-		cmd.Printf("DNS record %s updated!\n", dnsRecord)
+		printSetResults(cmd, results)
+		if err != nil {
+			return ambiguousHint(err)
+		}
+
+		if err := waitForPropagation(cmd, recordZone, updatedRecord(record, results)); err != nil {
+			return err
+		}
 
 		return nil
 	},
@@ -104,6 +82,8 @@ For example:
 func init() {
 	DnsCmd.AddCommand(updateCmd)
 
-	updateCmd.Flags().StringVarP(&recordType, "type", "t", "A", "The type of the DNS record")
-	updateCmd.Flags().StringVarP(&recordContent, "content", "c", "", "The content of the DNS record")
+	addZoneFlag(updateCmd)
+	addWaitFlag(updateCmd)
+	addRecordFlags(updateCmd)
+	addSelectFlags(updateCmd)
 }
@@ -23,6 +23,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"strings"
 
 	"github.com/akatranlp/akatran/cmd/dns"
+	dnsRepo "github.com/akatranlp/akatran/internal/dns"
 	"github.com/akatranlp/akatran/internal/viper"
 	"github.com/akatranlp/akatran/pkg/bytesize"
 	"github.com/joho/godotenv"
@@ -37,6 +39,7 @@ import (
 )
 
 var cfgFile string
+var verbose bool
 var storageSize bytesize.ByteSize = 100 * bytesize.GB
 var ram bytesize.ByteSize = 2 * bytesize.GiB
 
@@ -78,8 +81,14 @@ to quickly create a Cobra application.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func ExecuteContext(ctx context.Context) {
-	err := rootCmd.ExecuteContext(ctx)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
 	if err != nil {
+		cmd.PrintErrln(cmd.ErrPrefix(), err.Error())
+
+		var apiErr *dnsRepo.APIError
+		if verbose && errors.As(err, &apiErr) {
+			cmd.PrintErrln(apiErr.Details())
+		}
 		os.Exit(1)
 	}
 }
@@ -95,6 +104,9 @@ func addSubCommands() {
 func init() {
 	addSubCommands()
 
+	// Errors are printed by ExecuteContext to be able to add details with --verbose.
+	rootCmd.SilenceErrors = true
+
 	cobra.OnInitialize(initConfig)
 
 	// Here you will define your flags and configuration settings.
@@ -103,6 +115,8 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default is $XDG_CONFIG_HOME/%s/config.yaml)", rootCmd.Name()))
 
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "print the full error response of the provider")
+
 	rootCmd.PersistentFlags().VarP(&storageSize, "size", "s", "storage size")
 	viper.BindPFlag("size", rootCmd.PersistentFlags().Lookup("size"))
 	rootCmd.PersistentFlags().VarP(&ram, "ram", "r", "ram size")
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/miekg/dns"
+)
+
+const (
+	acmeChallengeLabel = "_acme-challenge"
+	// DefaultChallengeTTL is short, so a stale challenge value is not cached for long.
+	DefaultChallengeTTL = 120
+	// maxCNAMEHops limits how many CNAMEs are followed to find the challenge record.
+	maxCNAMEHops = 8
+)
+
+// ChallengeName returns the name of the DNS-01 challenge record of a domain. A wildcard
+// domain uses the record of its base domain and a name that already is a challenge
+// name, as passed by lego, is returned as is.
+func ChallengeName(domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	domain = strings.TrimPrefix(domain, "*.")
+	if strings.HasPrefix(domain, acmeChallengeLabel+".") {
+		return domain
+	}
+	return acmeChallengeLabel + "." + domain
+}
+
+// ChallengeValue returns the content of the challenge record for a key authorization,
+// the unpadded base64url encoded SHA-256 digest of RFC 8555 section 8.4.
+func ChallengeValue(keyAuthorization string) string {
+	digest := sha256.Sum256([]byte(keyAuthorization))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// challengeResolvers returns the resolvers used to follow CNAMEs, the resolvers of
+// propagation::resolvers or otherwise the ones of the system.
+func challengeResolvers() ([]string, error) {
+	if resolvers := viper.GetStringSlice("propagation::resolvers"); len(resolvers) > 0 {
+		return resolvers, nil
+	}
+
+	config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the system resolvers, set propagation::resolvers: %w", err)
+	}
+	return config.Servers, nil
+}
+
+// FollowChallengeCNAME returns the name the challenge record has to be created at. If the
+// challenge name is a CNAME, e.g. to delegate the challenges to a zone that only holds
+// them, the chain is followed to its end. Without a CNAME the name itself is returned.
+func FollowChallengeCNAME(ctx context.Context, name string) (string, error) {
+	resolvers, err := challengeResolvers()
+	if err != nil {
+		return "", err
+	}
+	if len(resolvers) == 0 {
+		return "", fmt.Errorf("no resolvers found to look up %s", name)
+	}
+
+	client := &dns.Client{Timeout: propagationQueryTimeout}
+	seen := []string{name}
+	for hop := 0; hop < maxCNAMEHops; hop++ {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(name), dns.TypeCNAME)
+
+		var res *dns.Msg
+		for _, resolver := range resolvers {
+			res, _, err = client.ExchangeContext(ctx, msg, withPort(resolver))
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to look up the CNAME of %s: %w", name, err)
+		}
+		if res.Rcode != dns.RcodeSuccess && res.Rcode != dns.RcodeNameError {
+			return "", fmt.Errorf("failed to look up the CNAME of %s: %s", name, dns.RcodeToString[res.Rcode])
+		}
+
+		target := ""
+		for _, rr := range res.Answer {
+			if cname, ok := rr.(*dns.CNAME); ok && strings.EqualFold(cname.Hdr.Name, dns.Fqdn(name)) {
+				target = strings.ToLower(strings.TrimSuffix(cname.Target, "."))
+			}
+		}
+		if target == "" {
+			return name, nil
+		}
+		if slices.Contains(seen, target) {
+			return "", fmt.Errorf("the CNAME of %s loops back to %s", seen[0], target)
+		}
+		seen = append(seen, target)
+		name = target
+	}
+	return "", fmt.Errorf("the CNAME chain of %s has more than %d hops", seen[0], maxCNAMEHops)
+}
@@ -0,0 +1,106 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/miekg/dns"
+)
+
+func TestChallengeName(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{domain: "example.com", want: "_acme-challenge.example.com"},
+		{domain: "www.example.com.", want: "_acme-challenge.www.example.com"},
+		{domain: "WWW.Example.com", want: "_acme-challenge.www.example.com"},
+		{domain: "*.example.com", want: "_acme-challenge.example.com"},
+		{domain: "_acme-challenge.example.com.", want: "_acme-challenge.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := ChallengeName(tt.domain); got != tt.want {
+				t.Errorf("ChallengeName(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallengeValue(t *testing.T) {
+	tests := []struct {
+		name             string
+		keyAuthorization string
+		want             string
+	}{
+		{
+			name:             "key authorization",
+			keyAuthorization: "evaGxfADs6pSRb2LAv9IZf17Dt3juxGJ-PCt92wr-oA.9jg46WB3rR_AHD-EBXdN7cBkH1WOu0tA3M9fm21mqTI",
+			want:             "lCM7cZyQXcVHK2nnW3jjAhNT3Fvm18UN-kWZZknKoYM",
+		},
+		{name: "empty", keyAuthorization: "", want: "47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChallengeValue(tt.keyAuthorization); got != tt.want {
+				t.Errorf("ChallengeValue(%q) = %q, want %q", tt.keyAuthorization, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowChallengeCNAME(t *testing.T) {
+	cnames := map[string]string{
+		"_acme-challenge.example.com.":      "_acme-challenge.example.net.",
+		"_acme-challenge.example.net.":      "example.acme.example.org.",
+		"_acme-challenge.loop.example.com.": "_acme-challenge.loop.example.net.",
+		"_acme-challenge.loop.example.net.": "_acme-challenge.loop.example.com.",
+	}
+	for i := 0; i <= maxCNAMEHops; i++ {
+		cnames["hop"+strings.Repeat("x", i)+".example.com."] = "hop" + strings.Repeat("x", i+1) + ".example.com."
+	}
+	server := newTestDNSServer(t, func(q dns.Question) []string {
+		target, ok := cnames[strings.ToLower(q.Name)]
+		if !ok || q.Qtype != dns.TypeCNAME {
+			return nil
+		}
+		return []string{q.Name + " 300 IN CNAME " + target}
+	})
+
+	viper.Set("propagation::resolvers", []string{server})
+	t.Cleanup(func() { viper.Set("propagation::resolvers", nil) })
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "_acme-challenge.www.example.com", want: "_acme-challenge.www.example.com"},
+		{name: "_acme-challenge.example.com", want: "example.acme.example.org"},
+		{name: "_acme-challenge.loop.example.com", wantErr: "loops back to _acme-challenge.loop.example.com"},
+		{name: "hop.example.com", wantErr: "has more than 8 hops"},
+		{name: "hopxx.example.com", want: "hop" + strings.Repeat("x", maxCNAMEHops+1) + ".example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FollowChallengeCNAME(context.Background(), tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FollowChallengeCNAME() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FollowChallengeCNAME() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FollowChallengeCNAME() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
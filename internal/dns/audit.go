@@ -0,0 +1,325 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a single record change made through a repository.
+type AuditEntry struct {
+	ID       string       `json:"id"`
+	Time     time.Time    `json:"time"`
+	User     string       `json:"user"`
+	Host     string       `json:"host"`
+	Command  string       `json:"command"`
+	Zone     string       `json:"zone"`
+	Provider string       `json:"provider"`
+	Action   ChangeAction `json:"action"`
+	Before   *DnsRecord   `json:"before,omitempty"`
+	After    *DnsRecord   `json:"after,omitempty"`
+}
+
+// Record returns the state of the record after the change, or before it for deletes.
+func (e AuditEntry) Record() DnsRecord {
+	if e.After != nil {
+		return *e.After
+	}
+	return *e.Before
+}
+
+type AuditEntryList []AuditEntry
+
+func (a AuditEntryList) tableColumns(wide bool) []tableColumn[AuditEntry] {
+	content := func(record *DnsRecord) string {
+		if record == nil {
+			return ""
+		}
+		return record.Content
+	}
+
+	columns := []tableColumn[AuditEntry]{
+		{header: "ID", value: func(e AuditEntry) string { return e.ID }},
+		{header: "TIME", value: func(e AuditEntry) string { return e.Time.Local().Format(time.DateTime) }},
+		{header: "ZONE", value: func(e AuditEntry) string { return e.Zone }},
+		{header: "ACTION", value: func(e AuditEntry) string { return string(e.Action) }},
+		{header: "TYPE", minWidth: 5, value: func(e AuditEntry) string { return e.Record().Type }, color: func(AuditEntry) string { return colorCyan }},
+		{header: "NAME", value: func(e AuditEntry) string { return e.Record().Name }},
+		{header: "BEFORE", maxWidth: contentMaxWidth / 2, value: func(e AuditEntry) string { return content(e.Before) }},
+		{header: "AFTER", maxWidth: contentMaxWidth / 2, value: func(e AuditEntry) string { return content(e.After) }},
+		{header: "USER", value: func(e AuditEntry) string { return e.User }},
+	}
+	if wide {
+		columns = append(columns,
+			tableColumn[AuditEntry]{header: "HOST", value: func(e AuditEntry) string { return e.Host }},
+			tableColumn[AuditEntry]{header: "COMMAND", maxWidth: contentMaxWidth, value: func(e AuditEntry) string { return e.Command }},
+		)
+	}
+	return columns
+}
+
+// RenderAuditEntries writes the entries of the audit log in the format of the renderer.
+func (r *Renderer) RenderAuditEntries(w io.Writer, entries AuditEntryList) error {
+	return render(w, r, entries, entries.tableColumns)
+}
+
+// AuditLog appends the changes of all repositories to a JSONL file.
+type AuditLog struct {
+	path    string
+	user    string
+	host    string
+	command string
+	mu      sync.Mutex
+}
+
+var auditLog *AuditLog
+
+// EnableAuditLog makes all repositories created from the config record their changes in the log.
+func EnableAuditLog(log *AuditLog) {
+	auditLog = log
+}
+
+// NewAuditLog creates the log at path for the changes of the command. Values of the
+// --token flag are not written to the log.
+func NewAuditLog(path string, args []string) *AuditLog {
+	username := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+	host, _ := os.Hostname()
+
+	return &AuditLog{path: path, user: username, host: host, command: redactCommand(args)}
+}
+
+func redactCommand(args []string) string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case i > 0 && args[i-1] == "--token":
+			arg = "***"
+		case strings.HasPrefix(arg, "--token="):
+			arg = "--token=***"
+		}
+		redacted[i] = arg
+	}
+	return strings.Join(redacted, " ")
+}
+
+func newAuditID() string {
+	id := make([]byte, 4)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func (l *AuditLog) append(entry AuditEntry) error {
+	entry.ID = newAuditID()
+	entry.Time = time.Now().UTC()
+	entry.User = l.user
+	entry.Host = l.host
+	entry.Command = l.command
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadAuditLog reads all entries of the log at path, the oldest first. A missing log has no entries.
+func ReadAuditLog(path string) (AuditEntryList, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return AuditEntryList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make(AuditEntryList, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit log entry in line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Find returns the entry with the ID, or the latest entry if id is empty.
+func (a AuditEntryList) Find(id string) (AuditEntry, error) {
+	if id == "" {
+		if len(a) == 0 {
+			return AuditEntry{}, fmt.Errorf("the audit log is empty")
+		}
+		return a[len(a)-1], nil
+	}
+	for _, entry := range a {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return AuditEntry{}, fmt.Errorf("no audit log entry with id %s", id)
+}
+
+// auditedRepo records every successful change of the wrapped repository in the audit log.
+type auditedRepo struct {
+	DnsRepository
+	zone     string
+	provider string
+	log      *AuditLog
+}
+
+func newAuditedRepo(repo DnsRepository, zone, provider string, log *AuditLog) DnsRepository {
+	if log == nil {
+		return repo
+	}
+	return &auditedRepo{DnsRepository: repo, zone: zone, provider: provider, log: log}
+}
+
+// UnwrapRepo returns the repository without the audit log, so the optional interfaces
+// of the provider like ZoneLister or BindExporter can be detected.
+func UnwrapRepo(repo DnsRepository) DnsRepository {
+	if audited, ok := repo.(*auditedRepo); ok {
+		return audited.DnsRepository
+	}
+	return repo
+}
+
+func (a *auditedRepo) record(action ChangeAction, before, after *DnsRecord) error {
+	err := a.log.append(AuditEntry{Zone: a.zone, Provider: a.provider, Action: action, Before: before, After: after})
+	if err != nil {
+		return fmt.Errorf("the change was made, but could not be written to the audit log: %w", err)
+	}
+	return nil
+}
+
+func (a *auditedRepo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	if err := a.DnsRepository.CreateRecord(ctx, record); err != nil {
+		return err
+	}
+	return a.record(ChangeCreate, nil, &record)
+}
+
+// UpdateRecord looks up the record the update applies to first, to know its state before the update.
+func (a *auditedRepo) UpdateRecord(ctx context.Context, record DnsRecord) error {
+	before, err := FindRecords(ctx, a.DnsRepository, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return err
+	}
+	idx, err := selectRecord(before, record)
+	if err != nil {
+		return err
+	}
+
+	if err := a.DnsRepository.UpdateRecord(ctx, record); err != nil {
+		return err
+	}
+	after := mergeRecord(before[idx], record)
+	return a.record(ChangeUpdate, &before[idx], &after)
+}
+
+func (a *auditedRepo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
+	deleted, err := a.DnsRepository.DeleteRecord(ctx, record)
+	for i := range deleted {
+		if logErr := a.record(ChangeDelete, &deleted[i], nil); logErr != nil {
+			err = errors.Join(err, logErr)
+		}
+	}
+	return deleted, err
+}
+
+// recordUnchanged reports whether the current record still has the state of the entry.
+func recordUnchanged(current, want DnsRecord) bool {
+	return sameRecord(current, want) && !attributesDiffer(current, want, false)
+}
+
+// Undo describes the inverse of the change of the entry.
+func (e AuditEntry) Undo() string {
+	switch e.Action {
+	case ChangeCreate:
+		return "delete " + describeRecord(*e.After)
+	case ChangeUpdate:
+		return fmt.Sprintf("update %s back to %s", describeRecord(*e.After), describeRecord(*e.Before))
+	default:
+		return fmt.Sprintf("create %s again", describeRecord(*e.Before))
+	}
+}
+
+// UndoEntry applies the inverse of the change of the entry. It refuses to undo the change
+// if the record was changed since, e.g. because the change was already undone.
+func UndoEntry(ctx context.Context, repo DnsRepository, entry AuditEntry) error {
+	record := entry.Record()
+	current, err := FindRecords(ctx, repo, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return err
+	}
+
+	switch entry.Action {
+	case ChangeCreate, ChangeUpdate:
+		idx := -1
+		for i := range current {
+			if recordUnchanged(current[i], *entry.After) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return fmt.Errorf("the %s record %s (%s) was changed or deleted since %s", record.Type, record.Name, entry.After.Content, entry.ID)
+		}
+
+		if entry.Action == ChangeCreate {
+			_, err := repo.DeleteRecord(ctx, DnsRecord{ID: current[idx].ID, Name: record.Name, Type: record.Type})
+			return err
+		}
+		// Every field is set, so a comment or tags the change added are removed again.
+		before := *entry.Before
+		before.ID = current[idx].ID
+		before = before.WithFields(allRecordFields)
+		return repo.UpdateRecord(ctx, before)
+	case ChangeDelete:
+		for _, existing := range current {
+			if sameRecord(existing, *entry.Before) {
+				return fmt.Errorf("the %s record %s (%s) was created again since %s", record.Type, record.Name, record.Content, entry.ID)
+			}
+		}
+		before := *entry.Before
+		before.ID = ""
+		return repo.CreateRecord(ctx, before)
+	}
+	return fmt.Errorf("invalid action %q in audit log entry %s", entry.Action, entry.ID)
+}
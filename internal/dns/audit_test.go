@@ -0,0 +1,130 @@
+package dns
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestAuditedRepo returns an audited MemoryRepo of example.com with the records and the path of its log.
+func newTestAuditedRepo(t *testing.T, records ...DnsRecord) (DnsRepository, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewAuditLog(path, []string{"akatran", "dns", "--token", "secret"})
+	return newAuditedRepo(newTestMemoryRepo(t, records...), "example.com", "memory", log), path
+}
+
+// lastAuditEntry returns the latest entry of the log at path.
+func lastAuditEntry(t *testing.T, path string) AuditEntry {
+	t.Helper()
+
+	entries, err := ReadAuditLog(path)
+	if err != nil {
+		t.Fatalf("ReadAuditLog() error = %v", err)
+	}
+	entry, err := entries.Find("")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	return entry
+}
+
+func TestUndoEntry(t *testing.T) {
+	www := DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}
+
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, repo DnsRepository) error
+		want   []DnsRecord
+	}{
+		{
+			name: "create",
+			change: func(ctx context.Context, repo DnsRepository) error {
+				return repo.CreateRecord(ctx, DnsRecord{Name: "api.example.com", Type: "A", Content: "192.0.2.2"})
+			},
+			want: []DnsRecord{www},
+		},
+		{
+			name: "update",
+			change: func(ctx context.Context, repo DnsRepository) error {
+				return repo.UpdateRecord(ctx, DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.3", TTL: 600, Comment: "added", Tags: []string{"env:prod"}})
+			},
+			want: []DnsRecord{www},
+		},
+		{
+			name: "delete",
+			change: func(ctx context.Context, repo DnsRepository) error {
+				_, err := repo.DeleteRecord(ctx, DnsRecord{Name: "www.example.com", Type: "A"})
+				return err
+			},
+			want: []DnsRecord{www},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, path := newTestAuditedRepo(t, www)
+			if err := tt.change(ctx, repo); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+
+			entry := lastAuditEntry(t, path)
+			if entry.Action != ChangeAction(tt.name) || strings.Contains(entry.Command, "secret") {
+				t.Errorf("audit entry = %+v, want a %s with the token redacted", entry, tt.name)
+			}
+			if err := UndoEntry(ctx, repo, entry); err != nil {
+				t.Fatalf("UndoEntry() error = %v", err)
+			}
+
+			records, err := repo.ListRecords(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("records after undo = %+v, want %+v", records, tt.want)
+			}
+			for i, want := range tt.want {
+				got := records[i]
+				if !sameRecord(got, want) || got.TTL != want.TTL || got.Comment != want.Comment || got.Tags != nil {
+					t.Errorf("record after undo = %+v, want %+v", got, want)
+				}
+			}
+
+			// The undo is recorded as well, and undoing the entry a second time is refused.
+			if undo := lastAuditEntry(t, path); undo.ID == entry.ID {
+				t.Errorf("the undo was not written to the audit log")
+			}
+			if err := UndoEntry(ctx, repo, entry); err == nil {
+				t.Errorf("UndoEntry() a second time error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestUndoEntryChangedSince(t *testing.T) {
+	ctx := context.Background()
+	repo, path := newTestAuditedRepo(t)
+
+	if err := repo.CreateRecord(ctx, DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+	created := lastAuditEntry(t, path)
+	if err := repo.UpdateRecord(ctx, DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.2"}); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+
+	err := UndoEntry(ctx, repo, created)
+	if err == nil || !strings.Contains(err.Error(), "was changed or deleted since "+created.ID) {
+		t.Fatalf("UndoEntry() error = %v, want the record to be reported as changed", err)
+	}
+	records, err := repo.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Content != "192.0.2.2" {
+		t.Errorf("records = %+v, want the updated record to be kept", records)
+	}
+}
@@ -0,0 +1,407 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchSet    BatchOp = "set"
+	BatchDelete BatchOp = "delete"
+)
+
+var batchOps = []BatchOp{BatchCreate, BatchUpdate, BatchSet, BatchDelete}
+
+// batchColumns lists the columns a CSV batch file may have, in the order of its header.
+var batchColumns = []string{"op", "name", "type", "content", "ttl", "priority", "proxied", "comment"}
+
+// BatchEntry is a single operation of a batch file.
+type BatchEntry struct {
+	// Line is the line of the entry in a CSV or JSONL file or its position in a JSON array.
+	Line int     `json:"-"`
+	Op   BatchOp `json:"op"`
+	DnsRecord
+}
+
+// normalize validates the entry. Create needs a complete record, update and set a valid
+// update and delete only the name and type and optionally the content of the records
+// to delete.
+func (e BatchEntry) normalize() (BatchEntry, error) {
+	e.Op = BatchOp(strings.ToLower(string(e.Op)))
+	if !slices.Contains(batchOps, e.Op) {
+		return e, fmt.Errorf("invalid op %q, use one of create, update, set, delete", e.Op)
+	}
+
+	e.Type = strings.ToUpper(e.Type)
+	if e.Type == "" {
+		return e, fmt.Errorf("type is required")
+	}
+	if err := ValidateRecordTypes(e.Type); err != nil {
+		return e, err
+	}
+
+	if e.Op == BatchDelete {
+		if !isHostname(e.Name) {
+			return e, fmt.Errorf("invalid record name: %s", e.Name)
+		}
+		e.Name = strings.TrimSuffix(e.Name, ".")
+		return e, nil
+	}
+
+	normalize := NormalizeUpdate
+	if e.Op == BatchCreate {
+		normalize = CompleteRecord
+	}
+	record, err := normalize(e.DnsRecord)
+	e.DnsRecord = record
+	return e, err
+}
+
+// run sends the operation of the entry to the repository of its zone.
+func (e BatchEntry) run(ctx context.Context, repo DnsRepository) error {
+	switch e.Op {
+	case BatchCreate:
+		return repo.CreateRecord(ctx, e.DnsRecord)
+	case BatchUpdate:
+		_, err := UpdateRecords(ctx, repo, e.DnsRecord, false)
+		return err
+	case BatchSet:
+		_, err := SetRecord(ctx, repo, e.DnsRecord, false)
+		return err
+	case BatchDelete:
+		_, err := repo.DeleteRecord(ctx, DnsRecord{ID: e.ID, Name: e.Name, Type: e.Type, Content: e.Content})
+		return err
+	}
+	return fmt.Errorf("invalid op %q", e.Op)
+}
+
+// ParseBatch reads the entries of a batch file. The format is detected from the content:
+// a JSON array of entries, one JSON entry per line or CSV with a header row. Every entry
+// is validated, so a broken file is rejected before anything is changed.
+func ParseBatch(r io.Reader) ([]BatchEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []BatchEntry
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("the batch is empty")
+	case trimmed[0] == '[':
+		entries, err = parseBatchJSON(trimmed)
+	case trimmed[0] == '{':
+		entries, err = parseBatchJSONL(data)
+	default:
+		entries, err = parseBatchCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		if entries[i], err = entry.normalize(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", entry.Line, err)
+		}
+	}
+	return entries, nil
+}
+
+func decodeBatchEntry(data []byte) (BatchEntry, error) {
+	var entry BatchEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&entry)
+	return entry, err
+}
+
+func parseBatchJSON(data []byte) ([]BatchEntry, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON batch: %w", err)
+	}
+
+	entries := make([]BatchEntry, 0, len(raw))
+	for i, message := range raw {
+		entry, err := decodeBatchEntry(message)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		entry.Line = i + 1
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseBatchJSONL(data []byte) ([]BatchEntry, error) {
+	entries := make([]BatchEntry, 0)
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		entry, err := decodeBatchEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entry.Line = i + 1
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseBatchCSV(data []byte) ([]BatchEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV batch: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(batchColumns, header[i]) {
+			return nil, fmt.Errorf("invalid CSV column %q, use %s", column, strings.Join(batchColumns, ", "))
+		}
+	}
+
+	entries := make([]BatchEntry, 0)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV batch: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		entry := BatchEntry{Line: line}
+		for i, value := range row {
+			if err := entry.setColumn(header[i], strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (e *BatchEntry) setColumn(column, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch column {
+	case "op":
+		e.Op = BatchOp(value)
+	case "name":
+		e.Name = value
+	case "type":
+		e.Type = value
+	case "content":
+		e.Content = value
+	case "ttl":
+		ttl, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid ttl %q", value)
+		}
+		e.TTL = uint32(ttl)
+		e.DnsRecord = e.WithFields(FieldTTL)
+	case "priority":
+		priority, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid priority %q", value)
+		}
+		e.Priority = uint16(priority)
+		e.DnsRecord = e.WithFields(FieldPriority)
+	case "proxied":
+		proxied, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid proxied %q", value)
+		}
+		e.Proxied = &proxied
+	case "comment":
+		e.Comment = value
+	}
+	return nil
+}
+
+// BatchResult is the outcome of a single entry of a batch.
+type BatchResult struct {
+	Line   int          `json:"line"`
+	Zone   string       `json:"zone,omitempty"`
+	Op     BatchOp      `json:"op"`
+	Status ResultStatus `json:"status"`
+	Record DnsRecord    `json:"record"`
+	Error  string       `json:"error,omitempty"`
+}
+
+type BatchResultList []BatchResult
+
+func (b BatchResultList) recordResults() RecordResultList {
+	results := make(RecordResultList, len(b))
+	for i, result := range b {
+		results[i] = RecordResult{Action: ChangeAction(result.Op), Status: result.Status, Record: result.Record, Error: result.Error}
+	}
+	return results
+}
+
+// Summary describes how many entries succeeded, failed or were skipped.
+func (b BatchResultList) Summary() string {
+	return b.recordResults().Summary()
+}
+
+// Err returns an error if not every entry succeeded.
+func (b BatchResultList) Err() error {
+	return b.recordResults().Err()
+}
+
+func (b BatchResultList) AsTableString() string {
+	return renderTable(b, []tableColumn[BatchResult]{
+		{header: "LINE", rightAlign: true, value: func(r BatchResult) string { return strconv.Itoa(r.Line) }},
+		{header: "ZONE", value: func(r BatchResult) string { return r.Zone }},
+		{header: "OP", value: func(r BatchResult) string { return string(r.Op) }},
+		{header: "STATUS", value: func(r BatchResult) string { return string(r.Status) }},
+		{header: "TYPE", minWidth: 5, value: func(r BatchResult) string { return r.Record.Type }},
+		{header: "NAME", value: func(r BatchResult) string { return r.Record.Name }},
+		{header: "CONTENT", maxWidth: contentMaxWidth, value: func(r BatchResult) string { return r.Record.Content }},
+		{header: "ERROR", value: func(r BatchResult) string { return r.Error }},
+	})
+}
+
+func (b BatchResultList) AsJsonString() string {
+	var builder strings.Builder
+	json.NewEncoder(&builder).Encode(b)
+
+	return builder.String()
+}
+
+// ZoneResolver returns the zone of a record name and the repository of the zone.
+type ZoneResolver func(ctx context.Context, name string) (string, DnsRepository, error)
+
+// RunBatch resolves the zone of every distinct name once, before anything is started,
+// and runs the entries with at most concurrency requests at the same time. Entries for the same name and type run one after another in
+// the order of the batch, so e.g. a delete followed by a create of the same record works.
+// With stopOnError no entry is started after the first failure and nothing is started if
+// the zone of an entry can not be resolved.
+func RunBatch(ctx context.Context, entries []BatchEntry, resolve ZoneResolver, concurrency int, stopOnError bool) BatchResultList {
+	results := make(BatchResultList, len(entries))
+	repos := make([]DnsRepository, len(entries))
+	zoneRepos := make(map[string]DnsRepository)
+	type resolution struct {
+		zone string
+		repo DnsRepository
+		err  error
+	}
+	resolved := make(map[string]resolution)
+	var stopped atomic.Bool
+
+	for i, entry := range entries {
+		results[i] = BatchResult{Line: entry.Line, Op: entry.Op, Record: entry.DnsRecord}
+
+		name := strings.ToLower(entry.Name)
+		r, ok := resolved[name]
+		if !ok {
+			r.zone, r.repo, r.err = resolve(ctx, name)
+			resolved[name] = r
+		}
+		zone, repo, err := r.zone, r.repo, r.err
+		if err != nil {
+			results[i].Status = ResultFailed
+			results[i].Error = err.Error()
+			if stopOnError {
+				stopped.Store(true)
+			}
+			continue
+		}
+		// Entries of the same zone share the repository, so providers that lock their
+		// records in memory see the changes of each other.
+		if existing, ok := zoneRepos[zone]; ok {
+			repo = existing
+		}
+		zoneRepos[zone] = repo
+		results[i].Zone = zone
+		repos[i] = repo
+	}
+
+	chains := make([][]int, 0)
+	chainIndex := make(map[string]int)
+	for i, entry := range entries {
+		if repos[i] == nil {
+			continue
+		}
+		key := results[i].Zone + "|" + strings.ToLower(entry.Name) + "|" + entry.Type
+		idx, ok := chainIndex[key]
+		if !ok {
+			idx = len(chains)
+			chainIndex[key] = idx
+			chains = append(chains, nil)
+		}
+		chains[idx] = append(chains[idx], i)
+	}
+
+	skip := func(i int, reason string) {
+		results[i].Status = ResultSkipped
+		results[i].Error = reason
+	}
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	for _, chain := range chains {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for _, i := range chain {
+				skip(i, ctx.Err().Error())
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, i := range chain {
+				switch {
+				case stopped.Load():
+					skip(i, "stopped after an earlier error")
+					continue
+				case ctx.Err() != nil:
+					skip(i, ctx.Err().Error())
+					continue
+				}
+
+				if err := entries[i].run(ctx, repos[i]); err != nil {
+					results[i].Status = ResultFailed
+					results[i].Error = err.Error()
+					if stopOnError {
+						stopped.Store(true)
+					}
+					continue
+				}
+				results[i].Status = ResultSucceeded
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
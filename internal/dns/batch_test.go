@@ -0,0 +1,182 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []BatchEntry
+		wantErr string
+	}{
+		{
+			name:  "csv",
+			input: "op,name,type,content,ttl,priority,proxied,comment\ncreate,www.example.com,a,192.0.2.1,300,,true,web\ndelete,old.example.com.,A,,,,,\nset,example.com,MX,mx.example.com,,10,,\n",
+			want: []BatchEntry{
+				{Line: 2, Op: BatchCreate, DnsRecord: DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300, Proxied: boolPtr(true), Comment: "web"}},
+				{Line: 3, Op: BatchDelete, DnsRecord: DnsRecord{Name: "old.example.com", Type: "A"}},
+				{Line: 4, Op: BatchSet, DnsRecord: DnsRecord{Name: "example.com", Type: "MX", Content: "mx.example.com", Priority: 10}},
+			},
+		},
+		{
+			name:  "csv with reordered columns",
+			input: "Type, Name, Op, Content\nAAAA, www.example.com, UPDATE, 2001:db8::1\n",
+			want: []BatchEntry{
+				{Line: 2, Op: BatchUpdate, DnsRecord: DnsRecord{Name: "www.example.com", Type: "AAAA", Content: "2001:db8::1"}},
+			},
+		},
+		{
+			name:  "json",
+			input: `[{"op":"create","name":"www.example.com","type":"A","content":"192.0.2.1"},{"op":"delete","name":"old.example.com","type":"A","content":"192.0.2.9"}]`,
+			want: []BatchEntry{
+				{Line: 1, Op: BatchCreate, DnsRecord: DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}},
+				{Line: 2, Op: BatchDelete, DnsRecord: DnsRecord{Name: "old.example.com", Type: "A", Content: "192.0.2.9"}},
+			},
+		},
+		{
+			name:  "jsonl",
+			input: "{\"op\":\"create\",\"name\":\"www.example.com\",\"type\":\"A\",\"content\":\"192.0.2.1\"}\n\n{\"op\":\"create\",\"name\":\"example.com\",\"type\":\"CAA\",\"content\":\"letsencrypt.org\"}\n",
+			want: []BatchEntry{
+				{Line: 1, Op: BatchCreate, DnsRecord: DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}},
+				{Line: 3, Op: BatchCreate, DnsRecord: DnsRecord{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Tag: DefaultCAATag}},
+			},
+		},
+		{name: "empty", input: " \n", wantErr: "the batch is empty"},
+		{name: "invalid op", input: "op,name,type,content\nupsert,www.example.com,A,192.0.2.1\n", wantErr: "line 2: invalid op"},
+		{name: "missing type", input: "op,name,content\ncreate,www.example.com,192.0.2.1\n", wantErr: "line 2: type is required"},
+		{name: "unsupported type", input: "op,name,type,content\ncreate,www.example.com,SOA,x\n", wantErr: "line 2:"},
+		{name: "invalid content", input: "op,name,type,content\ncreate,www.example.com,A,192.0.2.1\ncreate,api.example.com,A,nope\n", wantErr: "line 3:"},
+		{name: "invalid delete name", input: "op,name,type\ndelete,not a name,A\n", wantErr: "line 2: invalid record name"},
+		{name: "unknown csv column", input: "op,name,type,value\ncreate,www.example.com,A,192.0.2.1\n", wantErr: `invalid CSV column "value"`},
+		{name: "invalid ttl", input: "op,name,type,content,ttl\ncreate,www.example.com,A,192.0.2.1,soon\n", wantErr: `line 2: invalid ttl "soon"`},
+		{name: "invalid proxied", input: "op,name,type,content,proxied\ncreate,www.example.com,A,192.0.2.1,maybe\n", wantErr: `line 2: invalid proxied "maybe"`},
+		{name: "unknown json field", input: `[{"op":"create","name":"www.example.com","type":"A","value":"192.0.2.1"}]`, wantErr: "entry 1:"},
+		{name: "broken jsonl line", input: "{\"op\":\"create\",\"name\":\"www.example.com\",\"type\":\"A\",\"content\":\"192.0.2.1\"}\n{\"op\":\n", wantErr: "line 2:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBatch(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseBatch() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBatch() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBatch() = %d entries, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !sameBatchEntry(got[i], tt.want[i]) {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func sameBatchEntry(a, b BatchEntry) bool {
+	sameProxied := (a.Proxied == nil) == (b.Proxied == nil) && (a.Proxied == nil || *a.Proxied == *b.Proxied)
+	return a.Line == b.Line && a.Op == b.Op && a.Name == b.Name &&
+		sameRecord(a.DnsRecord, b.DnsRecord) &&
+		a.TTL == b.TTL && sameProxied && a.Comment == b.Comment
+}
+
+func TestRunBatch(t *testing.T) {
+	entries, err := ParseBatch(strings.NewReader(`op,name,type,content
+create,www.example.com,A,192.0.2.1
+create,app.lab.example.com,A,192.0.2.2
+delete,www.example.com,A,192.0.2.1
+create,www.example.com,A,192.0.2.3
+update,missing.example.com,A,192.0.2.4
+create,api.example.com,A,192.0.2.5
+create,www.example.net,A,192.0.2.6
+`))
+	if err != nil {
+		t.Fatalf("ParseBatch() error = %v", err)
+	}
+
+	zones := map[string]*MemoryRepo{
+		"example.com":     newTestMemoryRepo(t),
+		"lab.example.com": newTestMemoryRepo(t),
+	}
+	var resolves atomic.Int32
+	resolve := func(ctx context.Context, name string) (string, DnsRepository, error) {
+		resolves.Add(1)
+		zone := "example.com"
+		if strings.HasSuffix(name, ".lab.example.com") {
+			zone = "lab.example.com"
+		}
+		if !strings.HasSuffix(name, zone) {
+			return "", nil, errors.New("no zone for " + name)
+		}
+		return zone, zones[zone], nil
+	}
+
+	results := RunBatch(context.Background(), entries, resolve, 4, false)
+
+	statuses := make([]string, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, result.Zone+" "+string(result.Status))
+	}
+	want := []string{
+		"example.com succeeded",
+		"lab.example.com succeeded",
+		"example.com succeeded",
+		"example.com succeeded",
+		"example.com failed",
+		"example.com succeeded",
+		" failed",
+	}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("RunBatch() statuses = %q, want %q", statuses, want)
+	}
+	if got := resolves.Load(); got != 5 {
+		t.Errorf("resolver called %d times, want once for each of the 5 distinct names", got)
+	}
+
+	www, err := FindRecords(context.Background(), zones["example.com"], DnsRecord{Name: "www.example.com", Type: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(www) != 1 || www[0].Content != "192.0.2.3" {
+		t.Errorf("www records = %+v, want only 192.0.2.3", www)
+	}
+}
+
+func TestRunBatchStopOnError(t *testing.T) {
+	entries, err := ParseBatch(strings.NewReader(`op,name,type,content
+create,www.example.net,A,192.0.2.1
+create,api.example.com,A,192.0.2.2
+`))
+	if err != nil {
+		t.Fatalf("ParseBatch() error = %v", err)
+	}
+
+	repo := newTestMemoryRepo(t)
+	resolve := func(ctx context.Context, name string) (string, DnsRepository, error) {
+		if !strings.HasSuffix(name, ".example.com") {
+			return "", nil, errors.New("no zone for " + name)
+		}
+		return "example.com", repo, nil
+	}
+
+	results := RunBatch(context.Background(), entries, resolve, 1, true)
+	if results[0].Status != ResultFailed || results[1].Status != ResultSkipped {
+		t.Errorf("RunBatch() = %+v, want the first entry failed and the second skipped", results)
+	}
+	if records, _ := repo.ListRecords(context.Background()); len(records) != 0 {
+		t.Errorf("records = %+v, want nothing created", records)
+	}
+}
@@ -0,0 +1,122 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// BindExporter is implemented by repositories that can export the zone file natively,
+// which keeps details the generic export can not see, like the SOA record.
+type BindExporter interface {
+	ExportBind(ctx context.Context) (string, error)
+}
+
+// ExportBind renders the records of the zone as RFC 1035 zone file. The providers do not
+// expose the SOA record, so it is generated with the first NS record as primary server.
+func ExportBind(zone string, records DnsRecordList, now time.Time) (string, error) {
+	origin := dns.Fqdn(zone)
+
+	primary := "ns1." + origin
+	for _, record := range records {
+		if record.Type == "NS" && strings.EqualFold(record.Name, zone) {
+			primary = dns.Fqdn(record.Content)
+			break
+		}
+	}
+	serial, _ := strconv.ParseUint(now.UTC().Format("20060102")+"00", 10, 32)
+
+	soa := &dns.SOA{
+		Hdr:     dns.RR_Header{Name: origin, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: zoneFileDefaultTTL},
+		Ns:      primary,
+		Mbox:    "hostmaster." + origin,
+		Serial:  uint32(serial),
+		Refresh: 7200,
+		Retry:   3600,
+		Expire:  1209600,
+		Minttl:  300,
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "; %s exported by akatran at %s\n", zone, now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(&sb, "$TTL %d\n", zoneFileDefaultTTL)
+	fmt.Fprintln(&sb, formatZoneFileLine(soa, "generated, the provider does not expose the SOA record"))
+
+	for _, record := range records {
+		// A TTL of 1 is the automatic TTL of Cloudflare.
+		ttl := record.TTL
+		if ttl <= 1 {
+			ttl = zoneFileDefaultTTL
+		}
+
+		rr, err := dnsRecordToRR(record, ttl)
+		if err != nil {
+			return "", fmt.Errorf("failed to export %s record %s: %w", record.Type, record.Name, err)
+		}
+		fmt.Fprintln(&sb, formatZoneFileLine(rr, record.Comment))
+	}
+
+	return sb.String(), nil
+}
+
+// ParseBind reads the records of a zone file. The SOA record is ignored, records of
+// other types that are not supported are not returned but described in skipped.
+func ParseBind(content string, zone string) (DnsRecordList, []string, error) {
+	parsed, err := parseZoneFile(content, zone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := make(DnsRecordList, 0)
+	skipped := make([]string, 0)
+	for _, entry := range parsed.entries {
+		if entry.rr == nil || entry.rr.Header().Rrtype == dns.TypeSOA {
+			continue
+		}
+
+		record := entry.record()
+		if !slices.Contains(SupportedRecordTypes, record.Type) {
+			skipped = append(skipped, fmt.Sprintf("%s %s: unsupported record type", record.Type, record.Name))
+			continue
+		}
+		if !isInZone(strings.ToLower(record.Name), zone) {
+			return nil, nil, fmt.Errorf("%s record %s is not part of zone %s", record.Type, record.Name, zone)
+		}
+
+		record, err := NormalizeRecord(record)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s record %s: %w", record.Type, record.Name, err)
+		}
+		records = append(records, record)
+	}
+
+	return sortDnsRecords(records), skipped, nil
+}
+
+// NewImportPlan returns a plan that creates the records which do not exist in the zone yet.
+func NewImportPlan(ctx context.Context, repo DnsRepository, zone string, records DnsRecordList) (*Plan, error) {
+	current, err := repo.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	for i, record := range records {
+		if slices.ContainsFunc(current, func(existing DnsRecord) bool { return sameRecord(existing, record) }) {
+			continue
+		}
+		changes = append(changes, Change{Action: ChangeCreate, After: &records[i]})
+	}
+
+	return &Plan{
+		Zone:        zone,
+		Fingerprint: Fingerprint(current),
+		Changes:     changes,
+	}, nil
+}
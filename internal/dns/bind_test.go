@@ -0,0 +1,138 @@
+package dns
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withoutIDs clears the IDs ParseBind derives from the record data.
+func withoutIDs(records DnsRecordList) DnsRecordList {
+	for i := range records {
+		records[i].ID = ""
+	}
+	return records
+}
+
+func TestParseBind(t *testing.T) {
+	content := `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1 hostmaster 1 7200 3600 1209600 300
+@	IN	NS	ns1.example.net.
+@	300	IN	A	192.0.2.1
+www	IN	CNAME	@
+api.example.com.	IN	AAAA	2001:db8::1
+@	IN	MX	10 mail ; primary mail server
+_sip._tcp	IN	SRV	10 5 5060 sip
+@	IN	CAA	128 issue "letsencrypt.org"
+@	IN	TXT	"v=spf1 -all"
+@	IN	HINFO	"CPU" "OS"
+$ORIGIN dev.example.com.
+api	IN	A	192.0.2.2
+`
+
+	records, skipped, err := ParseBind(content, "example.com")
+	if err != nil {
+		t.Fatalf("ParseBind() error = %v", err)
+	}
+
+	want := DnsRecordList{
+		{Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		{Name: "api.dev.example.com", Type: "A", Content: "192.0.2.2", TTL: 3600},
+		{Name: "api.example.com", Type: "AAAA", Content: "2001:db8::1", TTL: 3600},
+		{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 3600},
+		{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10, TTL: 3600, Comment: "primary mail server"},
+		{Name: "example.com", Type: "TXT", Content: "v=spf1 -all", TTL: 3600},
+		{Name: "example.com", Type: "NS", Content: "ns1.example.net", TTL: 3600},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060, TTL: 3600},
+		{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128, Tag: "issue", TTL: 3600},
+	}
+	if !reflect.DeepEqual(withoutIDs(records), want) {
+		t.Errorf("ParseBind() =\n%s\nwant:\n%s", records.AsTableString(), want.AsTableString())
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "HINFO example.com") {
+		t.Errorf("ParseBind() skipped = %q, want the HINFO record", skipped)
+	}
+
+	if _, _, err := ParseBind("www.example.net. 300 IN A 192.0.2.1\n", "example.com"); err == nil {
+		t.Error("ParseBind() expected an error for a record outside of the zone")
+	}
+}
+
+func TestExportBind(t *testing.T) {
+	records := DnsRecordList{
+		{Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 1},
+		{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10, TTL: 3600, Comment: "primary mail server"},
+		{Name: "example.com", Type: "NS", Content: "ns1.example.net", TTL: 3600},
+		{Name: "example.com", Type: "TXT", Content: "v=spf1 -all", TTL: 3600},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060, TTL: 3600},
+		{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128, Tag: "issue", TTL: 3600},
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	content, err := ExportBind("example.com", records, now)
+	if err != nil {
+		t.Fatalf("ExportBind() error = %v", err)
+	}
+	for _, want := range []string{"$ORIGIN example.com.\n", "SOA\tns1.example.net. hostmaster.example.com. 2024050100 "} {
+		if !strings.Contains(content, want) {
+			t.Errorf("ExportBind() =\n%s\nwant it to contain %q", content, want)
+		}
+	}
+
+	parsed, _, err := ParseBind(content, "example.com")
+	if err != nil {
+		t.Fatalf("ParseBind() error = %v\n%s", err, content)
+	}
+	// The automatic TTL of Cloudflare is exported as default TTL.
+	want := sortDnsRecords(records)
+	want[1].TTL = zoneFileDefaultTTL
+	if !reflect.DeepEqual(withoutIDs(parsed), DnsRecordList(want)) {
+		t.Errorf("ParseBind(ExportBind()) =\n%s\nwant:\n%s", parsed.AsTableString(), DnsRecordList(want).AsTableString())
+	}
+
+	if _, err := ExportBind("example.com", DnsRecordList{{Name: "example.com", Type: "A", Content: "invalid"}}, now); err == nil {
+		t.Error("ExportBind() expected an error for an invalid record")
+	}
+}
+
+func TestNewImportPlan(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t,
+		DnsRecord{Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		DnsRecord{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10},
+	)
+	records, _, err := ParseBind(`$ORIGIN example.com.
+@	3600	IN	A	192.0.2.1
+@	3600	IN	MX	20 mail
+www	3600	IN	CNAME	@
+`, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := NewImportPlan(ctx, repo, "example.com", records)
+	if err != nil {
+		t.Fatalf("NewImportPlan() error = %v", err)
+	}
+
+	// Existing records are kept even if the TTL differs, only the data is compared.
+	want := []string{"create CNAME www.example.com example.com", "create MX example.com mail.example.com"}
+	if got := planSummary(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewImportPlan() = %q, want %q", got, want)
+	}
+
+	if err := plan.Apply(ctx, repo, 1).Err(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	plan, err = NewImportPlan(ctx, repo, "example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("NewImportPlan() after the import = %q, want no changes", planSummary(plan))
+	}
+}
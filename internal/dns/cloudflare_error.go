@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIMessage is an entry of the errors or messages array of a Cloudflare response.
+type APIMessage struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (m APIMessage) String() string {
+	if m.Code == 0 {
+		return m.Message
+	}
+	return fmt.Sprintf("%s (code %d)", m.Message, m.Code)
+}
+
+// APIError is returned when the Cloudflare API rejects a request.
+type APIError struct {
+	// Operation describes the failed request, e.g. "create record".
+	Operation  string
+	StatusCode int
+	Errors     []APIMessage
+	Messages   []APIMessage
+	// Payload is the raw response body.
+	Payload []byte
+
+	err error
+}
+
+// Code returns the code of the first error reported by Cloudflare or 0 if there is none.
+func (e *APIError) Code() int {
+	if len(e.Errors) == 0 {
+		return 0
+	}
+	return e.Errors[0].Code
+}
+
+// Cause returns a short human-readable reason of the failure.
+func (e *APIError) Cause() string {
+	causes := make([]string, 0, len(e.Errors))
+	for _, msg := range e.Errors {
+		causes = append(causes, msg.String())
+	}
+	if len(causes) == 0 {
+		causes = append(causes, e.rawCause())
+	}
+	return fmt.Sprintf("%s [HTTP %d]", strings.Join(causes, "; "), e.StatusCode)
+}
+
+// maxRawCauseLength limits how much of a body that is not JSON is shown as the cause.
+const maxRawCauseLength = 200
+
+// rawCause returns the start of the body if it is not JSON, e.g. the HTML page of a
+// proxy in front of the API, and the status text otherwise.
+func (e *APIError) rawCause() string {
+	body := strings.TrimSpace(string(e.Payload))
+	if body == "" || json.Valid(e.Payload) {
+		return http.StatusText(e.StatusCode)
+	}
+	if len(body) > maxRawCauseLength {
+		body = strings.ToValidUTF8(body[:maxRawCauseLength], "") + "..."
+	}
+	return strings.Join(strings.Fields(body), " ")
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.err, e.Cause())
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Details returns the operation, the HTTP status and the full response payload.
+func (e *APIError) Details() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "operation: %s\n", e.Operation)
+	fmt.Fprintf(&sb, "status: %d %s\n", e.StatusCode, http.StatusText(e.StatusCode))
+	for _, msg := range e.Messages {
+		fmt.Fprintf(&sb, "message: %s\n", msg)
+	}
+
+	var payload bytes.Buffer
+	if err := json.Indent(&payload, e.Payload, "", "  "); err != nil {
+		payload.Reset()
+		payload.Write(e.Payload)
+	}
+	fmt.Fprintf(&sb, "payload:\n%s", strings.TrimSpace(payload.String()))
+	return sb.String()
+}
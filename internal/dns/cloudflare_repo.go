@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/akatranlp/akatran/internal/utils"
 )
 
 const CloudflareProvider string = "cloudflare"
@@ -18,32 +25,151 @@ var (
 	ErrDomainNotFound    = fmt.Errorf("domain not found")
 	ErrListZonesFailed   = fmt.Errorf("failed to list zones")
 	ErrListRecordsFailed = fmt.Errorf("failed to list records")
+
+	ErrCreateRecordFailed  = fmt.Errorf("failed to create record")
+	ErrUpdateRecordFailed  = fmt.Errorf("failed to update record")
+	ErrDeleteRecordFailed  = fmt.Errorf("failed to delete record")
+	ErrExportRecordsFailed = fmt.Errorf("failed to export records")
+)
+
+const (
+	cloudflareBaseURL = "https://api.cloudflare.com/client/v4"
+
+	cloudflareDefaultPageSize = 100
+	cloudflareMinPageSize     = 5
+	cloudflareMaxPageSize     = 5000
+	cloudflareZonesPageSize   = 50
 )
 
 type CloudflareRepo struct {
-	domain string
-	token  string
-	client *http.Client
+	domain   string
+	token    string
+	client   *http.Client
+	baseURL  string
+	pageSize int
 }
 
 func NewCloudflareRepo(domain, token string, client *http.Client) *CloudflareRepo {
 	return &CloudflareRepo{
-		domain: domain,
-		token:  token,
-		client: client,
+		domain:   domain,
+		token:    token,
+		client:   client,
+		baseURL:  cloudflareBaseURL,
+		pageSize: cloudflareDefaultPageSize,
 	}
 }
 
-type listRecordsResponse struct {
-	Result []cloudflareDnsRecord `json:"result"`
+// SetBaseURL points the repository to another API endpoint, e.g. a local stand-in.
+func (c *CloudflareRepo) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetPageSize sets how many records are requested per page when listing records.
+func (c *CloudflareRepo) SetPageSize(size int) error {
+	if size < cloudflareMinPageSize || size > cloudflareMaxPageSize {
+		return fmt.Errorf("page size has to be between %d and %d", cloudflareMinPageSize, cloudflareMaxPageSize)
+	}
+	c.pageSize = size
+	return nil
 }
 
 type cloudflareDnsRecord struct {
-	ID      string `json:"id,omitempty"`
-	Type    string `json:"type,omitempty"`
-	Name    string `json:"name,omitempty"`
-	Content string `json:"content,omitempty"`
-	TTL     int    `json:"ttl,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Content  string          `json:"content,omitempty"`
+	Priority *uint16         `json:"priority,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	TTL      uint32          `json:"ttl,omitempty"`
+	Proxied  *bool           `json:"proxied,omitempty"`
+	Comment  string          `json:"comment,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+}
+
+// cloudflareRecordData holds the structured data Cloudflare uses for SRV and CAA records.
+type cloudflareRecordData struct {
+	Priority uint16 `json:"priority"`
+	Weight   uint16 `json:"weight"`
+	Port     uint16 `json:"port"`
+	Target   string `json:"target"`
+	Flags    uint8  `json:"flags"`
+	Tag      string `json:"tag"`
+	Value    string `json:"value"`
+}
+
+// newCloudflareDnsRecord converts the record into the request body. Unset attributes are
+// left out, so a PATCH request keeps their current values.
+func newCloudflareDnsRecord(record DnsRecord) (*cloudflareDnsRecord, error) {
+	cfRecord := &cloudflareDnsRecord{
+		Name:    record.Name,
+		Type:    record.Type,
+		TTL:     record.TTL,
+		Proxied: record.Proxied,
+		Comment: record.Comment,
+		Tags:    record.Tags,
+	}
+
+	var err error
+	switch record.Type {
+	case "MX":
+		cfRecord.Content = record.Content
+		cfRecord.Priority = &record.Priority
+	case "SRV":
+		cfRecord.Data, err = json.Marshal(map[string]any{
+			"priority": record.Priority,
+			"weight":   record.Weight,
+			"port":     record.Port,
+			"target":   record.Content,
+		})
+	case "CAA":
+		cfRecord.Data, err = json.Marshal(map[string]any{
+			"flags": record.Flags,
+			"tag":   record.Tag,
+			"value": record.Content,
+		})
+	default:
+		cfRecord.Content = record.Content
+	}
+
+	return cfRecord, err
+}
+
+func (r cloudflareDnsRecord) toDnsRecord() (DnsRecord, error) {
+	record := DnsRecord{
+		ID:      r.ID,
+		Name:    r.Name,
+		Type:    r.Type,
+		Content: r.Content,
+		TTL:     r.TTL,
+		Proxied: r.Proxied,
+		Comment: r.Comment,
+		Tags:    r.Tags,
+	}
+
+	var data cloudflareRecordData
+	if len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return DnsRecord{}, fmt.Errorf("invalid data of %s record %s: %w", r.Type, r.Name, err)
+		}
+	}
+
+	switch r.Type {
+	case "MX":
+		if r.Priority != nil {
+			record.Priority = *r.Priority
+		}
+	case "SRV":
+		record.Content = data.Target
+		record.Priority = data.Priority
+		record.Weight = data.Weight
+		record.Port = data.Port
+	case "CAA":
+		record.Content = data.Value
+		record.Flags = data.Flags
+		record.Tag = data.Tag
+	}
+
+	return record, nil
 }
 
 type cloudflareDnsZone struct {
@@ -51,126 +177,282 @@ type cloudflareDnsZone struct {
 	Name string `json:"name"`
 }
 
-func (c *CloudflareRepo) getZoneIDFromDomain(ctx context.Context, domain string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.cloudflare.com/client/v4/zones", nil)
-	if err != nil {
-		return "", err
-	}
+// errStopPaging can be returned by the callback of cloudflarePaginate to stop requesting further pages.
+var errStopPaging = errors.New("stop paging")
 
-	q := req.URL.Query()
-	q.Add("name", domain)
-	q.Add("status", "active")
-	req.URL.RawQuery = q.Encode()
+type cloudflareResultInfo struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Count      int `json:"count"`
+	TotalCount int `json:"total_count"`
+	TotalPages int `json:"total_pages"`
+}
+
+type cloudflareResponse struct {
+	Success    bool                  `json:"success"`
+	Errors     []APIMessage          `json:"errors"`
+	Messages   []APIMessage          `json:"messages"`
+	Result     json.RawMessage       `json:"result"`
+	ResultInfo *cloudflareResultInfo `json:"result_info"`
+}
 
+// do sends the request and decodes the response envelope. Requests that are rejected
+// by the API are returned as *APIError wrapping failErr.
+func (c *CloudflareRepo) do(req *http.Request, operation string, failErr error) (*cloudflareResponse, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: %w", failErr, err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return "", ErrListZonesFailed
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", failErr, err)
 	}
 
-	var zones struct {
-		Result []cloudflareDnsZone `json:"result"`
+	var body cloudflareResponse
+	decodeErr := json.Unmarshal(data, &body)
+	if res.StatusCode < 200 || res.StatusCode > 299 || decodeErr != nil || !body.Success {
+		return nil, newAPIError(operation, res.StatusCode, data, failErr)
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&zones); err != nil {
-		return "", err
+	return &body, nil
+}
+
+func newAPIError(operation string, statusCode int, data []byte, failErr error) *APIError {
+	var body cloudflareResponse
+	json.Unmarshal(data, &body)
+
+	return &APIError{
+		Operation:  operation,
+		StatusCode: statusCode,
+		Errors:     body.Errors,
+		Messages:   body.Messages,
+		Payload:    data,
+		err:        failErr,
+	}
+}
+
+// cloudflarePaginate requests the pages of a list endpoint one after another until
+// result_info reports the last page, and passes the results of each page to fn.
+func cloudflarePaginate[T any](ctx context.Context, c *CloudflareRepo, url string, query url.Values, pageSize int, operation string, failErr error, fn func(results []T) error) error {
+	for page := 1; ; page++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+
+		q := maps.Clone(query)
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(pageSize))
+		req.URL.RawQuery = q.Encode()
+
+		body, err := c.do(req, operation, failErr)
+		if err != nil {
+			return err
+		}
+
+		var results []T
+		if err := json.Unmarshal(body.Result, &results); err != nil {
+			return fmt.Errorf("%w: %w", failErr, err)
+		}
+
+		if err := fn(results); err != nil {
+			if errors.Is(err, errStopPaging) {
+				return nil
+			}
+			return err
+		}
+
+		if body.ResultInfo == nil || len(results) == 0 || page >= body.ResultInfo.TotalPages {
+			return nil
+		}
 	}
+}
 
-	if len(zones.Result) == 0 {
-		return "", ErrDomainNotFound
+func (c *CloudflareRepo) getZoneIDFromDomain(ctx context.Context, domain string) (string, error) {
+	query := url.Values{}
+	query.Set("name", domain)
+	query.Set("status", "active")
+
+	var zoneID string
+	err := cloudflarePaginate(ctx, c, c.baseURL+"/zones", query, cloudflareZonesPageSize, "list zones", ErrListZonesFailed, func(zones []cloudflareDnsZone) error {
+		for _, zone := range zones {
+			if zone.Name == domain {
+				zoneID = zone.ID
+				return errStopPaging
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %w", ErrGetZoneIDFailes, domain, err)
 	}
 
-	result := zones.Result[0]
-	if result.Name != domain {
-		return "", ErrDomainNotFound
+	if zoneID == "" {
+		return "", fmt.Errorf("%w: %s", ErrDomainNotFound, domain)
 	}
 
-	return result.ID, nil
+	return zoneID, nil
 }
 
-func (c *CloudflareRepo) listRecords(ctx context.Context, name string, type_ string) (*listRecordsResponse, error) {
-	zoneID, err := c.getZoneIDFromDomain(ctx, c.domain)
+// ListZones returns the names of all active zones of the account.
+func (c *CloudflareRepo) ListZones(ctx context.Context) ([]string, error) {
+	query := url.Values{}
+	query.Set("status", "active")
+
+	zones := make([]string, 0)
+	err := cloudflarePaginate(ctx, c, c.baseURL+"/zones", query, cloudflareZonesPageSize, "list zones", ErrListZonesFailed, func(page []cloudflareDnsZone) error {
+		for _, zone := range page {
+			zones = append(zones, zone.Name)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return zones, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.cloudflare.com/client/v4/zones/%s/dns_records", zoneID), nil)
+// ExportBind returns the zone file Cloudflare exports for the zone.
+func (c *CloudflareRepo) ExportBind(ctx context.Context) (string, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, c.domain)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	q := req.URL.Query()
-	q.Add("name", name)
-	q.Add("type", type_)
-	req.URL.RawQuery = q.Encode()
-
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/zones/%s/dns_records/export", c.baseURL, zoneID), nil)
+	if err != nil {
+		return "", err
+	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("%w: %w", ErrExportRecordsFailed, err)
 	}
 	defer res.Body.Close()
 
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrExportRecordsFailed, err)
+	}
 	if res.StatusCode != http.StatusOK {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
+		return "", newAPIError("export records", res.StatusCode, data, ErrExportRecordsFailed)
+	}
+	return string(data), nil
+}
+
+// eachRecord streams the records of the zone matching the query to fn, page by page.
+func (c *CloudflareRepo) eachRecord(ctx context.Context, zoneID string, query url.Values, fn func(record DnsRecord) error) error {
+	return cloudflarePaginate(ctx, c, fmt.Sprintf("%s/zones/%s/dns_records", c.baseURL, zoneID), query, c.pageSize, "list records", ErrListRecordsFailed, func(records []cloudflareDnsRecord) error {
+		for _, cfRecord := range records {
+			record, err := cfRecord.toDnsRecord()
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrListRecordsFailed, err)
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
 		}
-		return nil, fmt.Errorf("failed to list records: %s", string(data))
+		return nil
+	})
+}
+
+func (c *CloudflareRepo) listRecords(ctx context.Context, zoneID string, name string, type_ string) (DnsRecordList, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	if type_ != "" {
+		query.Set("type", type_)
 	}
 
-	var dnsRecords listRecordsResponse
-	if err := json.NewDecoder(res.Body).Decode(&dnsRecords); err != nil {
+	records := make(DnsRecordList, 0)
+	if err := c.eachRecord(ctx, zoneID, query, func(record DnsRecord) error {
+		records = append(records, record)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
-
-	return &dnsRecords, nil
+	return records, nil
 }
 
 func (c *CloudflareRepo) ListRecords(ctx context.Context, types ...string) (DnsRecordList, error) {
-	var err error
-	for _, t := range types {
-		if t != "A" && t != "AAAA" && t != "CNAME" {
-			return nil, fmt.Errorf("invalid record type: %s", t)
-		}
+	if err := ValidateRecordTypes(types...); err != nil {
+		return nil, err
 	}
 
-	var dnsRecords *listRecordsResponse
-	if len(types) == 0 || len(types) > 1 {
-		dnsRecords, err = c.listRecords(ctx, "", "")
-		if err != nil {
-			return nil, err
+	return c.ListFilteredRecords(ctx, RecordFilter{Types: types})
+}
+
+// cloudflareFilterQuery returns the query parameters for the parts of the filter the API
+// supports. Name globs are only pushed down if they are exact or a prefix or suffix match.
+func cloudflareFilterQuery(filter RecordFilter) url.Values {
+	const globChars = `*?[\`
+
+	query := url.Values{}
+	if name := strings.ToLower(strings.TrimSuffix(filter.Name, ".")); name != "" {
+		switch {
+		case !strings.ContainsAny(name, globChars):
+			query.Set("name", name)
+		case strings.HasPrefix(name, "*") && !strings.ContainsAny(name[1:], globChars):
+			query.Set("name.endswith", name[1:])
+		case strings.HasSuffix(name, "*") && !strings.ContainsAny(name[:len(name)-1], globChars):
+			query.Set("name.startswith", name[:len(name)-1])
 		}
-	} else {
-		dnsRecords, err = c.listRecords(ctx, "", types[0])
-		if err != nil {
-			return nil, err
+	}
+	if _, err := netip.ParsePrefix(filter.Content); filter.Content != "" && err != nil {
+		query.Set("content.contains", filter.Content)
+	}
+	if filter.Proxied != nil {
+		query.Set("proxied", strconv.FormatBool(*filter.Proxied))
+	}
+	if filter.Tag != "" {
+		if strings.Contains(filter.Tag, ":") {
+			query.Set("tag.exact", filter.Tag)
+		} else {
+			query.Set("tag.present", filter.Tag)
 		}
 	}
+	return query
+}
+
+// ListFilteredRecords lists the records with the name, content, proxy and tag filters
+// applied by the API. The API filters by a single type, so every type is requested on its own.
+func (c *CloudflareRepo) ListFilteredRecords(ctx context.Context, filter RecordFilter) (DnsRecordList, error) {
+	if err := ValidateRecordTypes(filter.Types...); err != nil {
+		return nil, err
+	}
 
+	zoneID, err := c.getZoneIDFromDomain(ctx, c.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	types := filter.Types
 	if len(types) == 0 {
-		types = []string{"A", "AAAA", "CNAME"}
+		types = []string{""}
 	}
 
-	records := make([]DnsRecord, 0)
-	for _, record := range dnsRecords.Result {
-		if !slices.Contains(types, record.Type) {
-			continue
+	records := make(DnsRecordList, 0)
+	for _, type_ := range types {
+		query := cloudflareFilterQuery(filter)
+		if type_ != "" {
+			query.Set("type", type_)
+		}
+
+		if err := c.eachRecord(ctx, zoneID, query, func(record DnsRecord) error {
+			if slices.Contains(SupportedRecordTypes, record.Type) {
+				records = append(records, record)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
 		}
-		records = append(records, DnsRecord{
-			Name:    record.Name,
-			Type:    record.Type,
-			Content: record.Content,
-		})
 	}
 
 	return sortDnsRecords(records), nil
@@ -182,82 +464,38 @@ func (c *CloudflareRepo) CreateRecord(ctx context.Context, record DnsRecord) err
 		return err
 	}
 
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(&cloudflareDnsRecord{
-		Name:    record.Name,
-		Type:    record.Type,
-		Content: record.Content,
-		TTL:     1,
-	}); err != nil {
-		return err
+	// A TTL of 1 lets Cloudflare choose the TTL automatically.
+	if record.TTL == 0 {
+		record.TTL = 1
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://api.cloudflare.com/client/v4/zones/%s/dns_records", zoneID), &buf)
+	cfRecord, err := newCloudflareDnsRecord(record)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(cfRecord); err != nil {
 		return err
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-
-			return err
-		}
-		return fmt.Errorf("failed to list records: %s", string(data))
-	}
-	return nil
-}
 
-func (c *CloudflareRepo) deleteSingleRecord(ctx context.Context, zoneID string, recordID string) error {
-	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("https://api.cloudflare.com/client/v4/zones/%s/dns_records/%s", zoneID, recordID), nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/zones/%s/dns_records", c.baseURL, zoneID), &buf)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
+	_, err = c.do(req, "create record", ErrCreateRecordFailed)
+	return err
+}
 
-	res, err := http.DefaultClient.Do(req)
+func (c *CloudflareRepo) deleteSingleRecord(ctx context.Context, zoneID string, recordID string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/zones/%s/dns_records/%s", c.baseURL, zoneID, recordID), nil)
 	if err != nil {
 		return err
 	}
 
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("failed to delete record: %s", string(data))
-	}
-	return nil
-}
-
-type deleteErr struct {
-	record cloudflareDnsRecord
-	err    error
-}
-
-type deleteErrList []deleteErr
-
-func (d deleteErrList) Error() string {
-	var builder strings.Builder
-
-	for _, err := range d {
-		fmt.Fprintf(&builder, "failed to delete record %s: %s\n", err.record.Name, err.err)
-	}
-
-	return builder.String()
+	_, err = c.do(req, "delete record", ErrDeleteRecordFailed)
+	return err
 }
 
 func (c *CloudflareRepo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
@@ -266,30 +504,30 @@ func (c *CloudflareRepo) DeleteRecord(ctx context.Context, record DnsRecord) (Dn
 		return nil, err
 	}
 
-	records, err := c.listRecords(ctx, record.Name, record.Type)
+	records, err := c.listRecords(ctx, zoneID, record.Name, record.Type)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(records.Result) == 0 {
-		return nil, fmt.Errorf("record not found")
+	records = utils.Filter(records, func(r DnsRecord) bool {
+		return r.matchesFilter(record)
+	})
+	if len(records) == 0 {
+		return nil, ErrRecordNotFound
 	}
 
 	successFullDeleted := make([]DnsRecord, 0)
 	errs := make(deleteErrList, 0)
 
-	for _, record := range records.Result {
+	for _, record := range records {
 		if err := c.deleteSingleRecord(ctx, zoneID, record.ID); err != nil {
 			errs = append(errs, deleteErr{
 				record: record,
 				err:    err,
 			})
+			continue
 		}
-		successFullDeleted = append(successFullDeleted, DnsRecord{
-			Name:    record.Name,
-			Type:    record.Type,
-			Content: record.Content,
-		})
+		successFullDeleted = append(successFullDeleted, record)
 	}
 
 	if len(errs) > 0 {
@@ -305,47 +543,49 @@ func (c *CloudflareRepo) UpdateRecord(ctx context.Context, record DnsRecord) err
 		return err
 	}
 
-	records, err := c.listRecords(ctx, record.Name, record.Type)
+	records, err := c.listRecords(ctx, zoneID, record.Name, record.Type)
 	if err != nil {
 		return err
 	}
 
-	if len(records.Result) == 0 {
-		return nil
-	}
-
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(&cloudflareDnsRecord{
-		Name:    record.Name,
-		Type:    records.Result[0].Type,
-		Content: record.Content,
-		TTL:     1,
-	}); err != nil {
+	idx, err := selectRecord(records, record)
+	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", fmt.Sprintf("https://api.cloudflare.com/client/v4/zones/%s/dns_records/%s", zoneID, records.Result[0].ID), &buf)
+	// Cloudflare replaces the priority of MX and the data of SRV and CAA records as a
+	// whole, so the fields the update does not set are taken from the record.
+	record = mergeRecord(records[idx], record)
+	// A TTL of 1 lets Cloudflare choose the TTL automatically.
+	if record.TTL == 0 {
+		record.TTL = 1
+	}
+	cfRecord, err := newCloudflareDnsRecord(record)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
+	// The comment and tags are always sent, so an update can remove them.
+	tags := cfRecord.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	update := struct {
+		*cloudflareDnsRecord
+		Comment string   `json:"comment"`
+		Tags    []string `json:"tags"`
+	}{cfRecord, cfRecord.Comment, tags}
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(update); err != nil {
 		return err
 	}
 
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("failed to delete record: %s", string(data))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", fmt.Sprintf("%s/zones/%s/dns_records/%s", c.baseURL, zoneID, records[idx].ID), &buf)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	_, err = c.do(req, "update record", ErrUpdateRecordFailed)
+	return err
 }
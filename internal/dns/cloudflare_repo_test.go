@@ -0,0 +1,72 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCloudflareRepoErrors(t *testing.T) {
+	const zones = `{"success":true,"result":[{"id":"zone","name":"example.com"}],"result_info":{"page":1,"total_pages":1}}`
+
+	tests := []struct {
+		name      string
+		records   func(w http.ResponseWriter)
+		wantErr   error
+		wantCause string
+	}{
+		{
+			name: "invalid record data",
+			records: func(w http.ResponseWriter) {
+				w.Write([]byte(`{"success":true,"result":[{"id":"1","type":"SRV","name":"_sip._tcp.example.com","data":"broken"}],"result_info":{"page":1,"total_pages":1}}`))
+			},
+			wantErr:   ErrListRecordsFailed,
+			wantCause: "invalid data of SRV record _sip._tcp.example.com",
+		},
+		{
+			name: "body that is not json",
+			records: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte("<html>\n<h1>502 Bad Gateway</h1>\n</html>\n"))
+			},
+			wantErr:   ErrListRecordsFailed,
+			wantCause: "<html> <h1>502 Bad Gateway</h1> </html> [HTTP 502]",
+		},
+		{
+			name: "json error without messages",
+			records: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"success":false,"errors":[]}`))
+			},
+			wantErr:   ErrListRecordsFailed,
+			wantCause: "Forbidden [HTTP 403]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/zones" {
+					w.Write([]byte(zones))
+					return
+				}
+				tt.records(w)
+			}))
+			defer server.Close()
+
+			repo := NewCloudflareRepo("example.com", "token", server.Client())
+			repo.SetBaseURL(server.URL)
+
+			_, err := repo.ListRecords(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListRecords() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantCause) {
+				t.Errorf("ListRecords() error = %v, want it to contain %q", err, tt.wantCause)
+			}
+		})
+	}
+}
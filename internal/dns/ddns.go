@@ -0,0 +1,122 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/akatran/internal/utils"
+)
+
+// DDNSAction describes what SyncAddress did to the record.
+type DDNSAction string
+
+const (
+	DDNSUnchanged DDNSAction = "unchanged"
+	DDNSCreated   DDNSAction = "created"
+	DDNSUpdated   DDNSAction = "updated"
+)
+
+// SyncAddress makes sure the A or AAAA record of name points to address. A missing
+// record is created, a drifted one is updated and nothing is sent if it already matches.
+func SyncAddress(ctx context.Context, repo DnsRepository, name, type_, address string) (DDNSAction, error) {
+	records, err := repo.ListRecords(ctx, type_)
+	if err != nil {
+		return "", err
+	}
+
+	matching := make(DnsRecordList, 0)
+	for _, record := range records {
+		if strings.EqualFold(record.Name, name) {
+			matching = append(matching, record)
+		}
+	}
+
+	record := DnsRecord{Name: name, Type: type_, Content: address}
+	switch {
+	case len(matching) == 0:
+		return DDNSCreated, repo.CreateRecord(ctx, record)
+	case len(matching) == 1 && matching[0].Content == address:
+		return DDNSUnchanged, nil
+	case len(matching) == 1:
+		return DDNSUpdated, repo.UpdateRecord(ctx, record)
+	}
+
+	for _, record := range matching {
+		if record.Content == address {
+			return DDNSUnchanged, nil
+		}
+	}
+	return "", fmt.Errorf("found %d %s records for %s, refusing to pick one", len(matching), type_, name)
+}
+
+// DDNSStateEntry is the last address that was confirmed for a record.
+type DDNSStateEntry struct {
+	Address   string    `json:"address"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// DDNSState remembers the confirmed addresses by record name and type, so unchanged
+// addresses do not cause API calls.
+type DDNSState struct {
+	path    string
+	Records map[string]DDNSStateEntry `json:"records"`
+}
+
+func ddnsStateKey(name, type_ string) string {
+	return strings.ToLower(name) + "/" + type_
+}
+
+// LoadDDNSState reads the state file. A missing file results in an empty state.
+func LoadDDNSState(path string) (*DDNSState, error) {
+	state := &DDNSState{
+		path:    path,
+		Records: make(map[string]DDNSStateEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to load ddns state from %s: %w", path, err)
+	}
+	if state.Records == nil {
+		state.Records = make(map[string]DDNSStateEntry)
+	}
+	return state, nil
+}
+
+// IsCurrent reports whether address was confirmed for the record within maxAge.
+func (s *DDNSState) IsCurrent(name, type_, address string, maxAge time.Duration) bool {
+	entry, ok := s.Records[ddnsStateKey(name, type_)]
+	return ok && entry.Address == address && time.Since(entry.CheckedAt) < maxAge
+}
+
+func (s *DDNSState) Set(name, type_, address string) {
+	s.Records[ddnsStateKey(name, type_)] = DDNSStateEntry{
+		Address:   address,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (s *DDNSState) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(s.path, data, 0o600)
+}
@@ -0,0 +1,216 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type DiffStatus string
+
+const (
+	// DiffMissing records only exist in the source.
+	DiffMissing DiffStatus = "missing"
+	// DiffExtra records only exist in the target.
+	DiffExtra DiffStatus = "extra"
+	// DiffChanged records exist on both sides with different TTL or proxy state.
+	DiffChanged DiffStatus = "changed"
+)
+
+type RecordDiff struct {
+	Status DiffStatus `json:"status"`
+	From   *DnsRecord `json:"from,omitempty"`
+	To     *DnsRecord `json:"to,omitempty"`
+}
+
+func (d RecordDiff) record() DnsRecord {
+	if d.From != nil {
+		return *d.From
+	}
+	return *d.To
+}
+
+type RecordDiffList []RecordDiff
+
+// ttlDiffers compares the TTLs of two providers. A TTL of 0 or 1 is the automatic
+// TTL of the provider and matches every TTL.
+func ttlDiffers(a, b uint32) bool {
+	return a > 1 && b > 1 && a != b
+}
+
+func proxiedDiffers(a, b *bool) bool {
+	return a != nil && b != nil && *a != *b
+}
+
+// DiffRecords compares the records of two zones record by record. Comments and tags
+// are not compared, because not every provider supports them.
+func DiffRecords(from, to DnsRecordList) RecordDiffList {
+	diffs := make(RecordDiffList, 0)
+	used := make([]bool, len(to))
+
+	for i := range from {
+		idx := -1
+		for j := range to {
+			if !used[j] && sameRecord(from[i], to[j]) {
+				idx = j
+				break
+			}
+		}
+
+		if idx < 0 {
+			diffs = append(diffs, RecordDiff{Status: DiffMissing, From: &from[i]})
+			continue
+		}
+
+		used[idx] = true
+		if ttlDiffers(from[i].TTL, to[idx].TTL) || proxiedDiffers(from[i].Proxied, to[idx].Proxied) {
+			diffs = append(diffs, RecordDiff{Status: DiffChanged, From: &from[i], To: &to[idx]})
+		}
+	}
+
+	for j := range to {
+		if !used[j] {
+			diffs = append(diffs, RecordDiff{Status: DiffExtra, To: &to[j]})
+		}
+	}
+
+	return diffs
+}
+
+// isApexNS reports whether the record is an NS record of the zone apex. These records
+// delegate the zone to the name servers of the provider that serves it, so every
+// provider has its own.
+func isApexNS(zone string, record DnsRecord) bool {
+	return record.Type == "NS" && strings.EqualFold(record.Name, zone)
+}
+
+// DiffZones lists the records of the zone at both repositories and compares them. The
+// NS records of the zone apex are left out unless includeNS is set, because they differ
+// between providers by design.
+func DiffZones(ctx context.Context, zone string, from, to DnsRepository, includeNS bool) (RecordDiffList, error) {
+	fromRecords, err := from.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	toRecords, err := to.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !includeNS {
+		isDelegation := func(record DnsRecord) bool { return isApexNS(zone, record) }
+		fromRecords = slices.DeleteFunc(fromRecords, isDelegation)
+		toRecords = slices.DeleteFunc(toRecords, isDelegation)
+	}
+	return DiffRecords(fromRecords, toRecords), nil
+}
+
+func (d RecordDiffList) AsTableString() string {
+	return renderTable(d, []tableColumn[RecordDiff]{
+		{header: "STATUS", value: func(r RecordDiff) string { return string(r.Status) }},
+		{header: "TYPE", minWidth: 5, value: func(r RecordDiff) string { return r.record().Type }},
+		{header: "NAME", value: func(r RecordDiff) string { return r.record().Name }},
+		{header: "CONTENT", value: func(r RecordDiff) string { return r.record().Content }},
+		{header: "DETAILS", value: func(r RecordDiff) string {
+			if r.Status != DiffChanged {
+				return ""
+			}
+			details := make([]string, 0)
+			if ttlDiffers(r.From.TTL, r.To.TTL) {
+				details = append(details, fmt.Sprintf("ttl %d -> %d", r.From.TTL, r.To.TTL))
+			}
+			if proxiedDiffers(r.From.Proxied, r.To.Proxied) {
+				details = append(details, fmt.Sprintf("proxied %s -> %s", strconv.FormatBool(*r.From.Proxied), strconv.FormatBool(*r.To.Proxied)))
+			}
+			return strings.Join(details, ", ")
+		}},
+	})
+}
+
+func (d RecordDiffList) AsJsonString() string {
+	var builder strings.Builder
+	json.NewEncoder(&builder).Encode(d)
+
+	return builder.String()
+}
+
+// Without returns the diffs without the given status.
+func (d RecordDiffList) Without(status DiffStatus) RecordDiffList {
+	diffs := make(RecordDiffList, 0, len(d))
+	for _, diff := range d {
+		if diff.Status != status {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// migrationRecord prepares a record of the source to be created at the target. The ID
+// of the source provider means nothing to the target.
+func migrationRecord(record DnsRecord) DnsRecord {
+	record.ID = ""
+	if record.TTL <= 1 {
+		record.TTL = 0
+	}
+	return record
+}
+
+// Migrate copies the differences of the source zone to the target with at most concurrency
+// requests at the same time. Missing records are created and changed ones updated in place.
+// Extra records of the target are only deleted with prune. Like a plan, the deletes run
+// first, then the updates and then the creates.
+func Migrate(ctx context.Context, to DnsRepository, diffs RecordDiffList, prune bool, concurrency int) RecordResultList {
+	ops := make([]RecordOperation, 0, len(diffs))
+	for _, diff := range diffs {
+		switch diff.Status {
+		case DiffMissing:
+			record := migrationRecord(*diff.From)
+			ops = append(ops, RecordOperation{
+				Action: ChangeCreate,
+				Record: record,
+				Run:    func(ctx context.Context) error { return to.CreateRecord(ctx, record) },
+			})
+		case DiffChanged:
+			// The ID of the target selects the record even if others share its name and type.
+			update := migrationRecord(*diff.From)
+			update.ID = diff.To.ID
+			ops = append(ops, RecordOperation{
+				Action: ChangeUpdate,
+				Record: migrationRecord(*diff.From),
+				Run:    func(ctx context.Context) error { return to.UpdateRecord(ctx, update) },
+			})
+		case DiffExtra:
+			if !prune {
+				continue
+			}
+			record := *diff.To
+			ops = append(ops, RecordOperation{
+				Action: ChangeDelete,
+				Record: record,
+				Run: func(ctx context.Context) error {
+					_, err := to.DeleteRecord(ctx, record)
+					return err
+				},
+			})
+		}
+	}
+
+	return runStagedOperations(ctx, concurrency, ops)
+}
+
+// VerifyMigration compares both zones again and returns the differences that remain.
+// Extra records of the target are only reported with prune and the NS records of the
+// zone apex only with includeNS, see DiffZones.
+func VerifyMigration(ctx context.Context, zone string, from, to DnsRepository, prune, includeNS bool) (RecordDiffList, error) {
+	diffs, err := DiffZones(ctx, zone, from, to, includeNS)
+	if err != nil {
+		return nil, err
+	}
+	if !prune {
+		diffs = diffs.Without(DiffExtra)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,272 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// newTestMemoryRepo returns a MemoryRepo of example.com with the records.
+func newTestMemoryRepo(t *testing.T, records ...DnsRecord) *MemoryRepo {
+	t.Helper()
+
+	repo, err := NewMemoryRepo("example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	return repo
+}
+
+// diffSummary describes the diffs as status, type, name and content for comparisons.
+func diffSummary(diffs RecordDiffList) []string {
+	summary := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		record := diff.record()
+		summary = append(summary, string(diff.Status)+" "+record.Type+" "+record.Name+" "+record.Content)
+	}
+	return summary
+}
+
+func TestDiffRecords(t *testing.T) {
+	a := DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}
+	mx := DnsRecord{Name: "example.com", Type: "MX", Content: "mx.example.com", Priority: 10, TTL: 300}
+
+	tests := []struct {
+		name string
+		from DnsRecordList
+		to   DnsRecordList
+		want []string
+	}{
+		{
+			name: "equal",
+			from: DnsRecordList{a, mx},
+			to:   DnsRecordList{mx, a},
+			want: []string{},
+		},
+		{
+			name: "missing and extra",
+			from: DnsRecordList{a},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.2", TTL: 300}},
+			want: []string{"missing A www.example.com 192.0.2.1", "extra A www.example.com 192.0.2.2"},
+		},
+		{
+			name: "changed ttl",
+			from: DnsRecordList{a},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 600}},
+			want: []string{"changed A www.example.com 192.0.2.1"},
+		},
+		{
+			name: "automatic ttl matches every ttl",
+			from: DnsRecordList{a},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 1}},
+			want: []string{},
+		},
+		{
+			name: "changed proxied",
+			from: DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Proxied: boolPtr(true)}},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Proxied: boolPtr(false)}},
+			want: []string{"changed A www.example.com 192.0.2.1"},
+		},
+		{
+			name: "unknown proxied state matches",
+			from: DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Proxied: boolPtr(true)}},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}},
+			want: []string{},
+		},
+		{
+			name: "comments and tags are ignored",
+			from: DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: "web", Tags: []string{"env:prod"}}},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}},
+			want: []string{},
+		},
+		{
+			name: "names are case insensitive and trailing dots ignored",
+			from: DnsRecordList{{Name: "WWW.example.com", Type: "CNAME", Content: "example.com."}},
+			to:   DnsRecordList{{Name: "www.example.com", Type: "CNAME", Content: "example.com"}},
+			want: []string{},
+		},
+		{
+			name: "different priority",
+			from: DnsRecordList{mx},
+			to:   DnsRecordList{{Name: "example.com", Type: "MX", Content: "mx.example.com", Priority: 20, TTL: 300}},
+			want: []string{"missing MX example.com mx.example.com", "extra MX example.com mx.example.com"},
+		},
+		{
+			name: "duplicates are matched once",
+			from: DnsRecordList{a, a},
+			to:   DnsRecordList{a},
+			want: []string{"missing A www.example.com 192.0.2.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffSummary(DiffRecords(tt.from, tt.to))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DiffRecords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffZones(t *testing.T) {
+	from := newTestMemoryRepo(t,
+		DnsRecord{Name: "example.com", Type: "NS", Content: "ns1.old-provider.net"},
+		DnsRecord{Name: "lab.example.com", Type: "NS", Content: "ns1.lab.example.com"},
+		DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"},
+	)
+	to := newTestMemoryRepo(t,
+		DnsRecord{Name: "example.com", Type: "NS", Content: "ns1.new-provider.net"},
+	)
+
+	tests := []struct {
+		name      string
+		includeNS bool
+		want      []string
+	}{
+		{
+			name: "apex NS is left out",
+			want: []string{"missing NS lab.example.com ns1.lab.example.com", "missing A www.example.com 192.0.2.1"},
+		},
+		{
+			name:      "apex NS is included",
+			includeNS: true,
+			want: []string{
+				"missing NS example.com ns1.old-provider.net",
+				"missing NS lab.example.com ns1.lab.example.com",
+				"missing A www.example.com 192.0.2.1",
+				"extra NS example.com ns1.new-provider.net",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := DiffZones(context.Background(), "example.com", from, to, tt.includeNS)
+			if err != nil {
+				t.Fatalf("DiffZones() error = %v", err)
+			}
+			got := diffSummary(diffs)
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DiffZones() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	from := newTestMemoryRepo(t,
+		DnsRecord{Name: "example.com", Type: "NS", Content: "ns1.old-provider.net"},
+		DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		DnsRecord{Name: "api.example.com", Type: "A", Content: "192.0.2.2", TTL: 300},
+	)
+	to := newTestMemoryRepo(t,
+		DnsRecord{Name: "example.com", Type: "NS", Content: "ns1.new-provider.net"},
+		DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 600},
+		DnsRecord{Name: "old.example.com", Type: "A", Content: "192.0.2.9", TTL: 300},
+	)
+	ctx := context.Background()
+
+	before, err := to.ListRecords(ctx, "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wwwID := before[slices.IndexFunc(before, func(r DnsRecord) bool { return r.Name == "www.example.com" })].ID
+
+	diffs, err := DiffZones(ctx, "example.com", from, to, false)
+	if err != nil {
+		t.Fatalf("DiffZones() error = %v", err)
+	}
+	if err := Migrate(ctx, to, diffs, true, 2).Err(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	remaining, err := VerifyMigration(ctx, "example.com", from, to, true, false)
+	if err != nil {
+		t.Fatalf("VerifyMigration() error = %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("VerifyMigration() = %q, want no differences", diffSummary(remaining))
+	}
+
+	records, err := to.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		switch record.Name {
+		case "example.com":
+			if record.Content != "ns1.new-provider.net" {
+				t.Errorf("apex NS = %s, want the NS of the target to be kept", record.Content)
+			}
+		case "www.example.com":
+			if record.ID != wwwID || record.TTL != 300 {
+				t.Errorf("www record = %+v, want id %s updated in place to ttl 300", record, wwwID)
+			}
+		case "old.example.com":
+			t.Errorf("old.example.com was not pruned")
+		}
+	}
+}
+
+// cnameConflictRepo rejects a CNAME next to other records of the name like the providers do.
+type cnameConflictRepo struct {
+	*MemoryRepo
+}
+
+func (r cnameConflictRepo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	existing, err := r.MemoryRepo.ListRecords(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.Name == record.Name && (record.Type == "CNAME" || other.Type == "CNAME") {
+			return errors.New("a CNAME cannot share its name with other records")
+		}
+	}
+	return r.MemoryRepo.CreateRecord(ctx, record)
+}
+
+func TestMigrateReplacesConflictingType(t *testing.T) {
+	from := newTestMemoryRepo(t,
+		DnsRecord{Name: "app.example.com", Type: "CNAME", Content: "www.example.com"},
+	)
+	to := cnameConflictRepo{newTestMemoryRepo(t,
+		DnsRecord{Name: "app.example.com", Type: "A", Content: "192.0.2.1"},
+	)}
+	ctx := context.Background()
+
+	diffs, err := DiffZones(ctx, "example.com", from, to, false)
+	if err != nil {
+		t.Fatalf("DiffZones() error = %v", err)
+	}
+	// A single request at a time would create the CNAME before the A record is deleted
+	// if the operations ran in the order of the diffs.
+	results := Migrate(ctx, to, diffs, true, 1)
+	if err := results.Err(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if results[0].Action != ChangeDelete || results[1].Action != ChangeCreate {
+		t.Errorf("Migrate() = %+v, want the delete before the create", results)
+	}
+
+	records, err := to.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Type != "CNAME" {
+		t.Errorf("records = %+v, want only the CNAME", records)
+	}
+}
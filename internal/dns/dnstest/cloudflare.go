@@ -0,0 +1,303 @@
+package dnstest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+)
+
+const cloudflareZoneID = "023e105f4ecef8ad9ca31a8372d0c353"
+
+type cloudflareRecord struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Name     string          `json:"name"`
+	Content  string          `json:"content"`
+	Priority *uint16         `json:"priority,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	TTL      uint32          `json:"ttl"`
+	Proxied  *bool           `json:"proxied,omitempty"`
+	Comment  string          `json:"comment,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+}
+
+// matches applies the record filters of the list endpoint that CloudflareRepo uses.
+func (r cloudflareRecord) matches(query url.Values) bool {
+	name, content := strings.ToLower(r.Name), strings.ToLower(r.Content)
+	switch {
+	case query.Has("name") && name != strings.ToLower(query.Get("name")),
+		query.Has("name.startswith") && !strings.HasPrefix(name, strings.ToLower(query.Get("name.startswith"))),
+		query.Has("name.endswith") && !strings.HasSuffix(name, strings.ToLower(query.Get("name.endswith"))),
+		query.Has("content.contains") && !strings.Contains(content, strings.ToLower(query.Get("content.contains"))),
+		query.Has("type") && r.Type != query.Get("type"),
+		query.Has("proxied") && (r.Proxied != nil && *r.Proxied) != (query.Get("proxied") == "true"),
+		query.Has("tag.exact") && !slices.Contains(r.Tags, query.Get("tag.exact")):
+		return false
+	}
+	if query.Has("tag.present") {
+		return slices.ContainsFunc(r.Tags, func(tag string) bool {
+			tagName, _, _ := strings.Cut(tag, ":")
+			return tagName == query.Get("tag.present")
+		})
+	}
+	return true
+}
+
+// CloudflareServer is a local stand-in for the parts of the Cloudflare API used by CloudflareRepo.
+type CloudflareServer struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	records []cloudflareRecord
+	nextID  int
+
+	// FailDelete makes the deletion of matching records answer with an internal server error.
+	FailDelete func(record dns.DnsRecord) bool
+}
+
+// NewCloudflareServer starts a stand-in serving an empty Domain.
+// The server is closed when the test finishes.
+func NewCloudflareServer(t *testing.T) *CloudflareServer {
+	t.Helper()
+
+	s := &CloudflareServer{
+		records:    make([]cloudflareRecord, 0),
+		FailDelete: never,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /client/v4/zones", s.listZones)
+	mux.HandleFunc("GET /client/v4/zones/{zone}/dns_records", s.listRecords)
+	mux.HandleFunc("POST /client/v4/zones/{zone}/dns_records", s.createRecord)
+	mux.HandleFunc("PATCH /client/v4/zones/{zone}/dns_records/{id}", s.updateRecord)
+	mux.HandleFunc("DELETE /client/v4/zones/{zone}/dns_records/{id}", s.deleteRecord)
+
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the base URL of the stand-in API to be passed to CloudflareRepo.SetBaseURL.
+func (s *CloudflareServer) URL() string {
+	return s.server.URL + "/client/v4"
+}
+
+// Client returns the http client to talk to the stand-in.
+func (s *CloudflareServer) Client() *http.Client {
+	return s.server.Client()
+}
+
+func (s *CloudflareServer) add(record cloudflareRecord) cloudflareRecord {
+	s.nextID++
+	record.ID = strconv.Itoa(s.nextID)
+	s.records = append(s.records, record)
+	return record
+}
+
+func writeResult(w http.ResponseWriter, status int, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": status == http.StatusOK,
+		"errors":  []any{},
+		"result":  result,
+	})
+}
+
+// writePage answers a list request with the page selected by the page and per_page parameters.
+func writePage[T any](w http.ResponseWriter, r *http.Request, results []T) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 100
+	}
+
+	start := min((page-1)*perPage, len(results))
+	end := min(start+perPage, len(results))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": true,
+		"errors":  []any{},
+		"result":  results[start:end],
+		"result_info": map[string]int{
+			"page":        page,
+			"per_page":    perPage,
+			"count":       end - start,
+			"total_count": len(results),
+			"total_pages": (len(results) + perPage - 1) / perPage,
+		},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"errors":  []any{map[string]any{"code": code, "message": message}},
+		"result":  nil,
+	})
+}
+
+func (s *CloudflareServer) checkZone(w http.ResponseWriter, r *http.Request) bool {
+	if r.PathValue("zone") != cloudflareZoneID {
+		writeError(w, http.StatusNotFound, 7003, "Could not route to /zones, perhaps your object identifier is invalid?")
+		return false
+	}
+	return true
+}
+
+func (s *CloudflareServer) listZones(w http.ResponseWriter, r *http.Request) {
+	zones := make([]any, 0)
+	if name := r.URL.Query().Get("name"); name == "" || name == Domain {
+		zones = append(zones, map[string]string{"id": cloudflareZoneID, "name": Domain})
+	}
+	writePage(w, r, zones)
+}
+
+func (s *CloudflareServer) listRecords(w http.ResponseWriter, r *http.Request) {
+	if !s.checkZone(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	records := make([]cloudflareRecord, 0)
+	for _, record := range s.records {
+		if record.matches(query) {
+			records = append(records, record)
+		}
+	}
+	writePage(w, r, records)
+}
+
+func (s *CloudflareServer) createRecord(w http.ResponseWriter, r *http.Request) {
+	if !s.checkZone(w, r) {
+		return
+	}
+
+	var record cloudflareRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		writeError(w, http.StatusBadRequest, 9207, "Request body is invalid.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResult(w, http.StatusOK, s.add(record))
+}
+
+func (s *CloudflareServer) updateRecord(w http.ResponseWriter, r *http.Request) {
+	if !s.checkZone(w, r) {
+		return
+	}
+
+	// The comment and tags are pointers to tell an empty value from a missing one.
+	var patch struct {
+		cloudflareRecord
+		Comment *string   `json:"comment"`
+		Tags    *[]string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, 9207, "Request body is invalid.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.records, func(record cloudflareRecord) bool {
+		return record.ID == r.PathValue("id")
+	})
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, 81044, "Record does not exist.")
+		return
+	}
+
+	record := &s.records[idx]
+	if patch.Name != "" {
+		record.Name = patch.Name
+	}
+	if patch.Type != "" {
+		record.Type = patch.Type
+	}
+	if patch.Content != "" {
+		record.Content = patch.Content
+	}
+	if patch.Priority != nil {
+		record.Priority = patch.Priority
+	}
+	if patch.Data != nil {
+		record.Data = patch.Data
+	}
+	if patch.TTL != 0 {
+		record.TTL = patch.TTL
+	}
+	if patch.Proxied != nil {
+		record.Proxied = patch.Proxied
+	}
+	if patch.Comment != nil {
+		record.Comment = *patch.Comment
+	}
+	if patch.Tags != nil {
+		record.Tags = *patch.Tags
+	}
+	writeResult(w, http.StatusOK, record)
+}
+
+func (s *CloudflareServer) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	if !s.checkZone(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.records, func(record cloudflareRecord) bool {
+		return record.ID == r.PathValue("id")
+	})
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, 81044, "Record does not exist.")
+		return
+	}
+
+	record := s.records[idx]
+	if s.FailDelete(dns.DnsRecord{Name: record.Name, Type: record.Type, Content: record.Content}) {
+		writeError(w, http.StatusInternalServerError, 10000, "Internal server error")
+		return
+	}
+
+	s.records = slices.Delete(s.records, idx, idx+1)
+	writeResult(w, http.StatusOK, map[string]string{"id": record.ID})
+}
+
+// CloudflareFactory runs CloudflareRepo against a fresh CloudflareServer.
+func CloudflareFactory(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository {
+	server := NewCloudflareServer(t)
+	repo := dns.NewCloudflareRepo(Domain, "test-token", server.Client())
+	repo.SetBaseURL(server.URL())
+	for _, record := range seed {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	server.FailDelete = failDelete
+	return repo
+}
@@ -0,0 +1,393 @@
+// Package dnstest provides a conformance suite every DnsRepository implementation
+// should pass, together with stand-ins to run it without touching a real provider.
+package dnstest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+)
+
+// Domain is the zone every repository under test has to manage.
+const Domain = "example.com"
+
+// Factory returns a repository for Domain that contains exactly the seed records.
+// Deleting a record for which failDelete returns true has to fail, while the
+// remaining records are still deleted unless the suite runs with AtomicDeletes.
+type Factory func(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository
+
+var seed = []dns.DnsRecord{
+	{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 300},
+	{Name: "dev.api.example.com", Type: "A", Content: "198.51.100.13"},
+	{Name: "example.com", Type: "AAAA", Content: "2001:db8::1"},
+	{Name: "api.example.com", Type: "A", Content: "198.51.100.11"},
+	{Name: "example.com", Type: "A", Content: "198.51.100.10"},
+	{Name: "api.example.com", Type: "A", Content: "198.51.100.12"},
+	{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Tag: "issue"},
+	{Name: "example.com", Type: "TXT", Content: "v=spf1 include:_spf.example.com -all"},
+	{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060},
+	{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10},
+}
+
+// sorted is the seed in the order sortDnsRecords has to produce.
+var sorted = []dns.DnsRecord{
+	{Name: "example.com", Type: "A", Content: "198.51.100.10"},
+	{Name: "api.example.com", Type: "A", Content: "198.51.100.11"},
+	{Name: "api.example.com", Type: "A", Content: "198.51.100.12"},
+	{Name: "dev.api.example.com", Type: "A", Content: "198.51.100.13"},
+	{Name: "example.com", Type: "AAAA", Content: "2001:db8::1"},
+	{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 300},
+	{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10},
+	{Name: "example.com", Type: "TXT", Content: "v=spf1 include:_spf.example.com -all"},
+	{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060},
+	{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Tag: "issue"},
+}
+
+// Option relaxes the contract for providers that cannot follow it.
+type Option int
+
+const (
+	// AtomicDeletes expects a delete that fails for one record to keep all of them,
+	// like a dynamic update that is applied as a whole.
+	AtomicDeletes Option = iota + 1
+)
+
+func never(dns.DnsRecord) bool { return false }
+
+// RunConformance runs the shared provider contract against the repositories built by factory.
+func RunConformance(t *testing.T, factory Factory, opts ...Option) {
+	ctx := context.Background()
+	atomicDeletes := slices.Contains(opts, AtomicDeletes)
+
+	t.Run("ListRecords/all", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, sorted)
+	})
+
+	t.Run("ListRecords/single type", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		records, err := repo.ListRecords(ctx, "A")
+		if err != nil {
+			t.Fatalf("ListRecords(A) error = %v", err)
+		}
+		assertRecords(t, records, filterTypes(sorted, "A"))
+	})
+
+	t.Run("ListRecords/multiple types", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		records, err := repo.ListRecords(ctx, "CNAME", "AAAA")
+		if err != nil {
+			t.Fatalf("ListRecords(CNAME, AAAA) error = %v", err)
+		}
+		assertRecords(t, records, filterTypes(sorted, "AAAA", "CNAME"))
+	})
+
+	t.Run("ListRecords/invalid type", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		if _, err := repo.ListRecords(ctx, "INVALID"); err == nil {
+			t.Fatal("ListRecords(INVALID) expected an error")
+		}
+	})
+
+	t.Run("CreateRecord", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		created := dns.DnsRecord{Name: "new.example.com", Type: "A", Content: "203.0.113.1"}
+		if err := repo.CreateRecord(ctx, created); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		want := slices.Clone(sorted)
+		want = slices.Insert(want, 4, created)
+		assertRecords(t, records, want)
+	})
+
+	t.Run("UpdateRecord", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		updated := dns.DnsRecord{Name: "www.example.com", Type: "CNAME", Content: "api.example.com"}
+		if err := repo.UpdateRecord(ctx, updated); err != nil {
+			t.Fatalf("UpdateRecord() error = %v", err)
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		// The TTL was not part of the update and has to be kept.
+		updated.TTL = 300
+		want := slices.Clone(sorted)
+		want[5] = updated
+		assertRecords(t, records, want)
+	})
+
+	t.Run("UpdateRecord/type specific fields", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		updates := []dns.DnsRecord{
+			{Name: "example.com", Type: "MX", Content: "mail2.example.com"},
+			{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip2.example.com"},
+			{Name: "example.com", Type: "CAA", Content: "pki.goog"},
+		}
+		for _, update := range updates {
+			if err := repo.UpdateRecord(ctx, update); err != nil {
+				t.Fatalf("UpdateRecord(%s) error = %v", update.Type, err)
+			}
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		// Priority, weight, port and tag were not part of the updates and have to be kept.
+		want := slices.Clone(sorted)
+		want[6].Content = "mail2.example.com"
+		want[8].Content = "sip2.example.com"
+		want[9].Content = "pki.goog"
+		assertRecords(t, records, want)
+	})
+
+	t.Run("UpdateRecord/zero values", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		updates := []dns.DnsRecord{
+			dns.DnsRecord{Name: "example.com", Type: "MX", Content: "mail.example.com"}.WithFields(dns.FieldPriority),
+			dns.DnsRecord{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com"}.WithFields(dns.FieldPriority | dns.FieldWeight),
+			{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128},
+			dns.DnsRecord{Name: "example.com", Type: "CAA", Content: "letsencrypt.org"}.WithFields(dns.FieldFlags),
+		}
+		for _, update := range updates {
+			if err := repo.UpdateRecord(ctx, update); err != nil {
+				t.Fatalf("UpdateRecord(%s) error = %v", update.Type, err)
+			}
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		// The fields set by the updates are 0 now, the port of the SRV record is kept.
+		want := slices.Clone(sorted)
+		want[6].Priority = 0
+		want[8].Priority = 0
+		want[8].Weight = 0
+		assertRecords(t, records, want)
+	})
+
+	t.Run("UpdateRecord/missing", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		err := repo.UpdateRecord(ctx, dns.DnsRecord{Name: "missing.example.com", Type: "A", Content: "203.0.113.1"})
+		if !errors.Is(err, dns.ErrRecordNotFound) {
+			t.Fatalf("UpdateRecord() error = %v, want %v", err, dns.ErrRecordNotFound)
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, sorted)
+	})
+
+	t.Run("UpdateRecord/ambiguous", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		err := repo.UpdateRecord(ctx, dns.DnsRecord{Name: "api.example.com", Type: "A", Content: "203.0.113.1"})
+		if !errors.Is(err, dns.ErrAmbiguousRecord) {
+			t.Fatalf("UpdateRecord() error = %v, want %v", err, dns.ErrAmbiguousRecord)
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, sorted)
+	})
+
+	t.Run("UpdateRecord/id", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		records, err := repo.ListRecords(ctx, "A")
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		idx := slices.IndexFunc(records, func(r dns.DnsRecord) bool { return r.Content == "198.51.100.12" })
+		if idx < 0 || records[idx].ID == "" {
+			t.Fatalf("ListRecords() did not return the record with an id\n%s", records.AsJsonString())
+		}
+
+		updated := dns.DnsRecord{ID: records[idx].ID, Name: "api.example.com", Type: "A", Content: "203.0.113.1"}
+		if err := repo.UpdateRecord(ctx, updated); err != nil {
+			t.Fatalf("UpdateRecord() error = %v", err)
+		}
+
+		records, err = repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		updated.ID = ""
+		want := slices.Clone(sorted)
+		want[2] = updated
+		assertRecords(t, records, want)
+	})
+
+	t.Run("DeleteRecord", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		deleted, err := repo.DeleteRecord(ctx, dns.DnsRecord{Name: "api.example.com", Type: "A"})
+		if err != nil {
+			t.Fatalf("DeleteRecord() error = %v", err)
+		}
+		assertRecords(t, deleted, sorted[1:3])
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, slices.Delete(slices.Clone(sorted), 1, 3))
+	})
+
+	t.Run("DeleteRecord/content", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		deleted, err := repo.DeleteRecord(ctx, dns.DnsRecord{Name: "api.example.com", Type: "A", Content: "198.51.100.12"})
+		if err != nil {
+			t.Fatalf("DeleteRecord() error = %v", err)
+		}
+		assertRecords(t, deleted, sorted[2:3])
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, slices.Delete(slices.Clone(sorted), 2, 3))
+	})
+
+	t.Run("DeleteRecord/id", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		records, err := repo.ListRecords(ctx, "A")
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		idx := slices.IndexFunc(records, func(r dns.DnsRecord) bool { return r.Content == "198.51.100.11" })
+		if idx < 0 || records[idx].ID == "" {
+			t.Fatalf("ListRecords() did not return the record with an id\n%s", records.AsJsonString())
+		}
+
+		deleted, err := repo.DeleteRecord(ctx, dns.DnsRecord{ID: records[idx].ID, Name: "api.example.com", Type: "A"})
+		if err != nil {
+			t.Fatalf("DeleteRecord() error = %v", err)
+		}
+		assertRecords(t, deleted, sorted[1:2])
+
+		records, err = repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, slices.Delete(slices.Clone(sorted), 1, 2))
+	})
+
+	t.Run("DeleteRecord/missing", func(t *testing.T) {
+		repo := factory(t, seed, never)
+
+		_, err := repo.DeleteRecord(ctx, dns.DnsRecord{Name: "missing.example.com", Type: "A"})
+		if !errors.Is(err, dns.ErrRecordNotFound) {
+			t.Fatalf("DeleteRecord() error = %v, want %v", err, dns.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("DeleteRecord/partial failure", func(t *testing.T) {
+		repo := factory(t, seed, func(record dns.DnsRecord) bool {
+			return record.Content == "198.51.100.12"
+		})
+
+		deleted, err := repo.DeleteRecord(ctx, dns.DnsRecord{Name: "api.example.com", Type: "A"})
+		if err == nil {
+			t.Fatal("DeleteRecord() expected an error for the failed record")
+		}
+		if !strings.Contains(err.Error(), "api.example.com") {
+			t.Errorf("DeleteRecord() error = %v, want it to name the failed record", err)
+		}
+
+		want := slices.Delete(slices.Clone(sorted), 1, 2)
+		if atomicDeletes {
+			if len(deleted) != 0 {
+				t.Errorf("DeleteRecord() = %v, want no records to be deleted", deleted)
+			}
+			want = sorted
+		} else {
+			assertRecords(t, deleted, sorted[1:2])
+		}
+
+		records, err := repo.ListRecords(ctx)
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		assertRecords(t, records, want)
+	})
+}
+
+func filterTypes(records []dns.DnsRecord, types ...string) []dns.DnsRecord {
+	return slices.DeleteFunc(slices.Clone(records), func(record dns.DnsRecord) bool {
+		return !slices.Contains(types, record.Type)
+	})
+}
+
+// matches compares two records. TTL and proxied state are only compared if they
+// are set in want, because providers fill in their defaults. IDs are assigned by the
+// provider and never compared.
+func matches(got, want dns.DnsRecord) bool {
+	got.ID = ""
+	if want.TTL == 0 {
+		got.TTL = 0
+	}
+	if want.Proxied == nil {
+		got.Proxied = nil
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// assertRecords checks the order by type and name and the contents regardless of the
+// order, because records sharing a type and name have no defined order.
+func assertRecords(t *testing.T, got dns.DnsRecordList, want []dns.DnsRecord) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d\ngot:\n%s\nwant:\n%s", len(got), len(want), got.AsTableString(), dns.DnsRecordList(want).AsTableString())
+	}
+
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Name != want[i].Name {
+			t.Fatalf("record %d is %s %s, want %s %s\ngot:\n%s", i, got[i].Type, got[i].Name, want[i].Type, want[i].Name, got.AsTableString())
+		}
+	}
+
+	byContent := func(a, b dns.DnsRecord) int {
+		return strings.Compare(a.Type+a.Name+a.Content, b.Type+b.Name+b.Content)
+	}
+	gotSorted := slices.Clone(got)
+	slices.SortFunc(gotSorted, byContent)
+	wantSorted := slices.Clone(want)
+	slices.SortFunc(wantSorted, byContent)
+	if !slices.EqualFunc(gotSorted, wantSorted, matches) {
+		t.Fatalf("got records:\n%s\nwant:\n%s", got.AsTableString(), dns.DnsRecordList(want).AsTableString())
+	}
+}
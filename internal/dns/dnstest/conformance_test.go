@@ -0,0 +1,23 @@
+package dnstest
+
+import "testing"
+
+func TestMemory(t *testing.T) {
+	RunConformance(t, MemoryFactory)
+}
+
+func TestCloudflare(t *testing.T) {
+	RunConformance(t, CloudflareFactory)
+}
+
+func TestHetzner(t *testing.T) {
+	RunConformance(t, HetznerFactory)
+}
+
+func TestZoneFile(t *testing.T) {
+	RunConformance(t, ZoneFileFactory)
+}
+
+func TestRFC2136(t *testing.T) {
+	RunConformance(t, RFC2136Factory, AtomicDeletes)
+}
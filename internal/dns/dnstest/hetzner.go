@@ -0,0 +1,232 @@
+package dnstest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+)
+
+const hetznerZoneID = "rMu2waTJPbHr4n2zmSXdGV"
+
+// hetznerMaxPageSize is smaller than the page size HetznerRepo asks for, so listing
+// the records of the conformance suite needs several pages.
+const hetznerMaxPageSize = 4
+
+type hetznerRecord struct {
+	ID     string `json:"id"`
+	ZoneID string `json:"zone_id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	TTL    uint32 `json:"ttl,omitempty"`
+}
+
+// HetznerServer is a local stand-in for the parts of the Hetzner DNS API used by HetznerRepo.
+type HetznerServer struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	records []hetznerRecord
+	nextID  int
+
+	// FailDelete makes the deletion of matching records answer with an internal server error.
+	FailDelete func(record dns.DnsRecord) bool
+}
+
+// NewHetznerServer starts a stand-in serving an empty Domain.
+// The server is closed when the test finishes.
+func NewHetznerServer(t *testing.T) *HetznerServer {
+	t.Helper()
+
+	s := &HetznerServer{
+		records:    make([]hetznerRecord, 0),
+		FailDelete: never,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/zones", s.listZones)
+	mux.HandleFunc("GET /api/v1/records", s.listRecords)
+	mux.HandleFunc("POST /api/v1/records", s.createRecord)
+	mux.HandleFunc("PUT /api/v1/records/{id}", s.updateRecord)
+	mux.HandleFunc("DELETE /api/v1/records/{id}", s.deleteRecord)
+
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the base URL of the stand-in API to be passed to HetznerRepo.SetBaseURL.
+func (s *HetznerServer) URL() string {
+	return s.server.URL + "/api/v1"
+}
+
+// Client returns the http client to talk to the stand-in.
+func (s *HetznerServer) Client() *http.Client {
+	return s.server.Client()
+}
+
+func writeHetzner(w http.ResponseWriter, status int, body map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeHetznerError(w http.ResponseWriter, status int, message string) {
+	writeHetzner(w, status, map[string]any{
+		"error": map[string]any{"message": message, "code": status},
+	})
+}
+
+// writeHetznerPage answers a list request with the page selected by the page and
+// per_page parameters, serving at most hetznerMaxPageSize entries.
+func writeHetznerPage[T any](w http.ResponseWriter, r *http.Request, key string, entries []T) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 || perPage > hetznerMaxPageSize {
+		perPage = hetznerMaxPageSize
+	}
+
+	start := min((page-1)*perPage, len(entries))
+	end := min(start+perPage, len(entries))
+
+	writeHetzner(w, http.StatusOK, map[string]any{
+		key: entries[start:end],
+		"meta": map[string]any{
+			"pagination": map[string]int{
+				"page":          page,
+				"per_page":      perPage,
+				"last_page":     max((len(entries)+perPage-1)/perPage, 1),
+				"total_entries": len(entries),
+			},
+		},
+	})
+}
+
+func (s *HetznerServer) listZones(w http.ResponseWriter, r *http.Request) {
+	zones := make([]map[string]string, 0)
+	if name := r.URL.Query().Get("name"); name == "" || name == Domain {
+		zones = append(zones, map[string]string{"id": hetznerZoneID, "name": Domain})
+	}
+	if len(zones) == 0 {
+		writeHetznerError(w, http.StatusNotFound, "zone not found")
+		return
+	}
+	writeHetznerPage(w, r, "zones", zones)
+}
+
+func (s *HetznerServer) listRecords(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("zone_id") != hetznerZoneID {
+		writeHetznerError(w, http.StatusNotFound, "zone not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeHetznerPage(w, r, "records", s.records)
+}
+
+func (s *HetznerServer) decodeRecord(w http.ResponseWriter, r *http.Request) (hetznerRecord, bool) {
+	var record hetznerRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		writeHetznerError(w, http.StatusUnprocessableEntity, "invalid request body")
+		return record, false
+	}
+	if record.ZoneID != hetznerZoneID {
+		writeHetznerError(w, http.StatusNotFound, "zone not found")
+		return record, false
+	}
+	if record.Name == "" || record.Type == "" || record.Value == "" {
+		writeHetznerError(w, http.StatusUnprocessableEntity, "name, type and value are required")
+		return record, false
+	}
+	return record, true
+}
+
+func (s *HetznerServer) createRecord(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.decodeRecord(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record.ID = strconv.Itoa(s.nextID)
+	s.records = append(s.records, record)
+	writeHetzner(w, http.StatusOK, map[string]any{"record": record})
+}
+
+func (s *HetznerServer) updateRecord(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.decodeRecord(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.records, func(existing hetznerRecord) bool {
+		return existing.ID == r.PathValue("id")
+	})
+	if idx < 0 {
+		writeHetznerError(w, http.StatusNotFound, "record not found")
+		return
+	}
+
+	record.ID = s.records[idx].ID
+	s.records[idx] = record
+	writeHetzner(w, http.StatusOK, map[string]any{"record": record})
+}
+
+func (s *HetznerServer) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.records, func(record hetznerRecord) bool {
+		return record.ID == r.PathValue("id")
+	})
+	if idx < 0 {
+		writeHetznerError(w, http.StatusNotFound, "record not found")
+		return
+	}
+
+	record := s.records[idx]
+	name := Domain
+	if record.Name != "@" {
+		name = record.Name + "." + Domain
+	}
+	if s.FailDelete(dns.DnsRecord{Name: name, Type: record.Type, Content: record.Value}) {
+		writeHetznerError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	s.records = slices.Delete(s.records, idx, idx+1)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HetznerFactory runs HetznerRepo against a fresh HetznerServer.
+func HetznerFactory(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository {
+	server := NewHetznerServer(t)
+	repo := dns.NewHetznerRepo(Domain, "test-token", server.Client())
+	repo.SetBaseURL(server.URL())
+	for _, record := range seed {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	server.FailDelete = failDelete
+	return repo
+}
@@ -0,0 +1,29 @@
+package dnstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+)
+
+// MemoryFactory runs MemoryRepo without persistence.
+func MemoryFactory(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository {
+	repo, err := dns.NewMemoryRepo(Domain, "")
+	if err != nil {
+		t.Fatalf("NewMemoryRepo() error = %v", err)
+	}
+	for _, record := range seed {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	repo.OnDelete = func(record dns.DnsRecord) error {
+		if failDelete(record) {
+			return errors.New("injected failure")
+		}
+		return nil
+	}
+	return repo
+}
@@ -0,0 +1,184 @@
+package dnstest
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+	miekg "github.com/miekg/dns"
+)
+
+const (
+	rfc2136KeyName = "akatran-test."
+	rfc2136Secret  = "c2VjcmV0LWtleS1vZi10aGUtc3RhbmQtaW4="
+)
+
+// RFC2136Server is a local stand-in for a name server that accepts TSIG signed dynamic
+// updates and zone transfers of Domain, like BIND or Knot configured for RFC2136Repo.
+type RFC2136Server struct {
+	server *miekg.Server
+	addr   string
+
+	mu      sync.Mutex
+	soa     miekg.RR
+	records []miekg.RR
+
+	// FailDelete makes an update that removes a matching record fail as a whole.
+	FailDelete func(record dns.DnsRecord) bool
+}
+
+// NewRFC2136Server starts a stand-in serving an empty Domain over TCP.
+// The server is shut down when the test finishes.
+func NewRFC2136Server(t *testing.T) *RFC2136Server {
+	t.Helper()
+
+	soa, err := miekg.NewRR(Domain + ". 3600 IN SOA ns1." + Domain + ". hostmaster." + Domain + ". 1 7200 3600 1209600 300")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &RFC2136Server{
+		addr:       listener.Addr().String(),
+		soa:        soa,
+		records:    make([]miekg.RR, 0),
+		FailDelete: never,
+	}
+	started := make(chan struct{})
+	s.server = &miekg.Server{
+		Listener:          listener,
+		TsigSecret:        map[string]string{rfc2136KeyName: rfc2136Secret},
+		Handler:           miekg.HandlerFunc(s.serve),
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc:     acceptUpdates,
+	}
+	go s.server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { s.server.Shutdown() })
+
+	return s
+}
+
+// Addr returns the address of the server.
+func (s *RFC2136Server) Addr() string {
+	return s.addr
+}
+
+// acceptUpdates accepts queries and dynamic updates, which the default of the server
+// rejects because their sections can hold any number of records.
+func acceptUpdates(header miekg.Header) miekg.MsgAcceptAction {
+	if header.Bits&(1<<15) != 0 {
+		return miekg.MsgIgnore
+	}
+	switch opcode := int(header.Bits>>11) & 0xF; opcode {
+	case miekg.OpcodeQuery, miekg.OpcodeUpdate:
+		return miekg.MsgAccept
+	}
+	return miekg.MsgRejectNotImplemented
+}
+
+func (s *RFC2136Server) serve(w miekg.ResponseWriter, req *miekg.Msg) {
+	res := new(miekg.Msg)
+	res.SetReply(req)
+
+	tsig := req.IsTsig()
+	if tsig == nil || w.TsigStatus() != nil {
+		res.Rcode = miekg.RcodeNotAuth
+		w.WriteMsg(res)
+		return
+	}
+
+	s.mu.Lock()
+	switch {
+	case req.Opcode == miekg.OpcodeUpdate:
+		res.Rcode = s.update(req)
+	case len(req.Question) == 1 && req.Question[0].Qtype == miekg.TypeAXFR:
+		res.Answer = append(append([]miekg.RR{s.soa}, s.records...), s.soa)
+	default:
+		res.Rcode = miekg.RcodeRefused
+	}
+	s.mu.Unlock()
+
+	res.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, int64(tsig.TimeSigned))
+	w.WriteMsg(res)
+}
+
+// update applies the insertions and removals of the update section. Like a real name
+// server it applies all of them or none.
+func (s *RFC2136Server) update(req *miekg.Msg) int {
+	if len(req.Question) != 1 || !strings.EqualFold(req.Question[0].Name, miekg.Fqdn(Domain)) {
+		return miekg.RcodeNotZone
+	}
+
+	records := slices.Clone(s.records)
+	for _, rr := range req.Ns {
+		header := rr.Header()
+		switch header.Class {
+		case miekg.ClassINET:
+			if !slices.ContainsFunc(records, func(existing miekg.RR) bool { return sameRR(existing, rr) }) {
+				records = append(records, rr)
+			}
+		case miekg.ClassNONE:
+			for _, existing := range records {
+				if sameRR(existing, rr) && s.FailDelete(toDnsRecord(existing)) {
+					return miekg.RcodeServerFailure
+				}
+			}
+			records = slices.DeleteFunc(records, func(existing miekg.RR) bool { return sameRR(existing, rr) })
+		case miekg.ClassANY:
+			records = slices.DeleteFunc(records, func(existing miekg.RR) bool {
+				return strings.EqualFold(existing.Header().Name, header.Name) &&
+					(header.Rrtype == miekg.TypeANY || existing.Header().Rrtype == header.Rrtype)
+			})
+		default:
+			return miekg.RcodeFormatError
+		}
+	}
+
+	s.records = records
+	return miekg.RcodeSuccess
+}
+
+// sameRR compares owner, type and data of the records, the TTL and class are ignored
+// like in the removals of an update.
+func sameRR(a, b miekg.RR) bool {
+	a, b = miekg.Copy(a), miekg.Copy(b)
+	for _, rr := range []miekg.RR{a, b} {
+		rr.Header().Class = miekg.ClassINET
+		rr.Header().Ttl = 0
+		rr.Header().Name = strings.ToLower(rr.Header().Name)
+	}
+	return miekg.IsDuplicate(a, b)
+}
+
+func toDnsRecord(rr miekg.RR) dns.DnsRecord {
+	records, _, err := dns.ParseBind(rr.String(), Domain)
+	if err != nil || len(records) != 1 {
+		return dns.DnsRecord{}
+	}
+	return records[0]
+}
+
+// RFC2136Factory runs RFC2136Repo against the stand-in. The deletes of RFC2136Repo
+// are a single update, so the suite has to be run with AtomicDeletes.
+func RFC2136Factory(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository {
+	server := NewRFC2136Server(t)
+	repo, err := dns.NewRFC2136Repo(Domain, server.Addr(), rfc2136KeyName, rfc2136Secret, "hmac-sha256")
+	if err != nil {
+		t.Fatalf("NewRFC2136Repo() error = %v", err)
+	}
+	for _, record := range seed {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	server.FailDelete = failDelete
+	return repo
+}
@@ -0,0 +1,40 @@
+package dnstest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akatranlp/akatran/internal/dns"
+)
+
+// emptyZoneFile is a zone file of Domain with only the SOA record, which every change
+// needs to bump the serial.
+const emptyZoneFile = `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 3600
+`
+
+// ZoneFileFactory runs ZoneFileRepo against a zone file in a temporary directory.
+func ZoneFileFactory(t *testing.T, seed []dns.DnsRecord, failDelete func(dns.DnsRecord) bool) dns.DnsRepository {
+	path := filepath.Join(t.TempDir(), Domain+".zone")
+	if err := os.WriteFile(path, []byte(emptyZoneFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := dns.NewZoneFileRepo(Domain, path)
+	for _, record := range seed {
+		if err := repo.CreateRecord(context.Background(), record); err != nil {
+			t.Fatalf("CreateRecord() error = %v", err)
+		}
+	}
+	repo.OnDelete = func(record dns.DnsRecord) error {
+		if failDelete(record) {
+			return errors.New("injected failure")
+		}
+		return nil
+	}
+	return repo
+}
@@ -0,0 +1,137 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/akatranlp/akatran/internal/utils"
+)
+
+// RecordFilter selects records by their attributes. Empty fields match every record.
+type RecordFilter struct {
+	Types []string
+	// Name is a glob like *.dev.example.com that has to match the full name, ignoring case.
+	Name string
+	// NameRegex is a regular expression that has to match somewhere in the full name.
+	NameRegex string
+	// Content is a CIDR range like 10.0.0.0/8 for A and AAAA records, otherwise a
+	// substring of the content, ignoring case.
+	Content string
+	MinTTL  uint32
+	MaxTTL  uint32
+	// Proxied selects proxied or not proxied records, records without a proxy state are
+	// not proxied.
+	Proxied *bool
+	// Tag is a tag name or a name:value pair, see HasTag.
+	Tag string
+
+	nameRegex *regexp.Regexp
+	prefix    *netip.Prefix
+}
+
+// Compile validates the filter and prepares the name regex and CIDR range. It has to be
+// called before Match.
+func (f *RecordFilter) Compile() error {
+	f.Types = utils.Map(f.Types, strings.ToUpper)
+	if err := ValidateRecordTypes(f.Types...); err != nil {
+		return err
+	}
+
+	if f.Name != "" {
+		if _, err := path.Match(f.Name, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", f.Name, err)
+		}
+	}
+
+	f.nameRegex = nil
+	if f.NameRegex != "" {
+		re, err := regexp.Compile("(?i)" + f.NameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid name regex %q: %w", f.NameRegex, err)
+		}
+		f.nameRegex = re
+	}
+
+	f.prefix = nil
+	if prefix, err := netip.ParsePrefix(f.Content); err == nil {
+		prefix = prefix.Masked()
+		f.prefix = &prefix
+	}
+
+	if f.MaxTTL > 0 && f.MinTTL > f.MaxTTL {
+		return fmt.Errorf("the minimum TTL %d is greater than the maximum TTL %d", f.MinTTL, f.MaxTTL)
+	}
+	return nil
+}
+
+func (f *RecordFilter) matchesName(name string) bool {
+	if f.Name != "" {
+		if ok, _ := path.Match(strings.ToLower(strings.TrimSuffix(f.Name, ".")), strings.ToLower(name)); !ok {
+			return false
+		}
+	}
+	return f.nameRegex == nil || f.nameRegex.MatchString(name)
+}
+
+func (f *RecordFilter) matchesContent(record DnsRecord) bool {
+	if f.prefix != nil {
+		if record.Type != "A" && record.Type != "AAAA" {
+			return false
+		}
+		addr, err := netip.ParseAddr(record.Content)
+		return err == nil && f.prefix.Contains(addr.Unmap())
+	}
+	return f.Content == "" || strings.Contains(strings.ToLower(record.Content), strings.ToLower(f.Content))
+}
+
+// Match reports whether the record matches every field of the filter.
+func (f *RecordFilter) Match(record DnsRecord) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, record.Type) {
+		return false
+	}
+	if !f.matchesName(record.Name) || !f.matchesContent(record) {
+		return false
+	}
+	if f.MinTTL > 0 && record.TTL < f.MinTTL {
+		return false
+	}
+	if f.MaxTTL > 0 && record.TTL > f.MaxTTL {
+		return false
+	}
+	if f.Proxied != nil && (record.Proxied != nil && *record.Proxied) != *f.Proxied {
+		return false
+	}
+	return f.Tag == "" || record.HasTag(f.Tag)
+}
+
+// FilteredLister is implemented by repositories that can apply filters in the provider
+// API. The result may still contain records that do not match every field of the filter.
+type FilteredLister interface {
+	ListFilteredRecords(ctx context.Context, filter RecordFilter) (DnsRecordList, error)
+}
+
+// FilterRecords lists the records that match the filter. The filters the provider supports
+// are pushed down to its API, the remaining ones are applied to the listed records.
+func FilterRecords(ctx context.Context, repo DnsRepository, filter RecordFilter) (DnsRecordList, error) {
+	if err := filter.Compile(); err != nil {
+		return nil, err
+	}
+
+	var records DnsRecordList
+	var err error
+	if lister, ok := UnwrapRepo(repo).(FilteredLister); ok {
+		records, err = lister.ListFilteredRecords(ctx, filter)
+	} else {
+		records, err = repo.ListRecords(ctx, filter.Types...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Filter(records, filter.Match), nil
+}
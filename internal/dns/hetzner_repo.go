@@ -0,0 +1,428 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/miekg/dns"
+)
+
+const HetznerProvider string = "hetzner"
+
+const hetznerBaseURL = "https://dns.hetzner.com/api/v1"
+
+// hetznerPageSize is the number of zones or records requested per page.
+const hetznerPageSize = 100
+
+type HetznerRepo struct {
+	domain  string
+	token   string
+	client  *http.Client
+	baseURL string
+}
+
+func NewHetznerRepo(domain, token string, client *http.Client) *HetznerRepo {
+	return &HetznerRepo{
+		domain:  domain,
+		token:   token,
+		client:  client,
+		baseURL: hetznerBaseURL,
+	}
+}
+
+// SetBaseURL points the repository to another API endpoint, e.g. a local stand-in.
+func (h *HetznerRepo) SetBaseURL(baseURL string) {
+	h.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+type hetznerDnsRecord struct {
+	ID     string `json:"id,omitempty"`
+	ZoneID string `json:"zone_id,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Value  string `json:"value,omitempty"`
+	TTL    uint32 `json:"ttl,omitempty"`
+}
+
+type hetznerDnsZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// toFQDN converts the zone relative record name used by Hetzner into a fully qualified name.
+func (h *HetznerRepo) toFQDN(name string) string {
+	if name == "@" || name == "" {
+		return h.domain
+	}
+	return name + "." + h.domain
+}
+
+// toRelative converts a fully qualified record name into the zone relative name used by Hetzner.
+// Names outside of the zone are rejected, as Hetzner would create them below the zone.
+func (h *HetznerRepo) toRelative(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == h.domain {
+		return "@", nil
+	}
+	if relative, ok := strings.CutSuffix(name, "."+h.domain); ok {
+		return relative, nil
+	}
+	return "", fmt.Errorf("%s is not in the zone %s", name, h.domain)
+}
+
+// toDnsRecord parses the zone file formatted value Hetzner uses for the record data.
+func (h *HetznerRepo) toDnsRecord(record hetznerDnsRecord) DnsRecord {
+	name := h.toFQDN(record.Name)
+
+	// Unquoted TXT values would be split at every space by the zone parser.
+	if record.Type == "TXT" && !strings.HasPrefix(record.Value, `"`) {
+		return DnsRecord{ID: record.ID, Name: name, Type: record.Type, Content: record.Value, TTL: record.TTL}
+	}
+
+	parser := dns.NewZoneParser(strings.NewReader(fmt.Sprintf("%s IN %s %s", dns.Fqdn(name), record.Type, record.Value)), dns.Fqdn(h.domain), "")
+	if rr, ok := parser.Next(); ok && parser.Err() == nil {
+		dnsRecord := rrToDnsRecord(rr)
+		dnsRecord.ID = record.ID
+		dnsRecord.TTL = record.TTL
+		return dnsRecord
+	}
+
+	return DnsRecord{ID: record.ID, Name: name, Type: record.Type, Content: record.Value, TTL: record.TTL}
+}
+
+func toHetznerValue(record DnsRecord) (string, error) {
+	rr, err := dnsRecordToRR(record, 0)
+	if err != nil {
+		return "", err
+	}
+	return rdataString(rr), nil
+}
+
+func (h *HetznerRepo) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, h.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Auth-API-Token", h.token)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// do sends the request and returns the response body. Requests that are rejected by
+// the API are returned as *APIError wrapping failErr.
+func (h *HetznerRepo) do(req *http.Request, operation string, failErr error) ([]byte, error) {
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", failErr, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", failErr, err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var body struct {
+			Error APIMessage `json:"error"`
+		}
+		json.Unmarshal(data, &body)
+
+		apiErr := &APIError{Operation: operation, StatusCode: res.StatusCode, Payload: data, err: failErr}
+		if body.Error.Message != "" {
+			apiErr.Errors = []APIMessage{body.Error}
+		}
+		return nil, apiErr
+	}
+
+	return data, nil
+}
+
+// hetznerPaginate requests the pages of a list endpoint one after another until the
+// pagination of the response reports the last page and returns the entries under key.
+func hetznerPaginate[T any](ctx context.Context, h *HetznerRepo, path, key string, query url.Values, operation string, failErr error) ([]T, error) {
+	results := make([]T, 0)
+	for page := 1; ; page++ {
+		req, err := h.newRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		q := maps.Clone(query)
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(hetznerPageSize))
+		req.URL.RawQuery = q.Encode()
+
+		data, err := h.do(req, operation, failErr)
+		if err != nil {
+			return nil, err
+		}
+
+		var body map[string]json.RawMessage
+		var entries []T
+		var meta struct {
+			Pagination struct {
+				LastPage int `json:"last_page"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, fmt.Errorf("%w: %w", failErr, err)
+		}
+		if err := json.Unmarshal(body[key], &entries); err != nil {
+			return nil, fmt.Errorf("%w: %w", failErr, err)
+		}
+		if len(body["meta"]) > 0 {
+			if err := json.Unmarshal(body["meta"], &meta); err != nil {
+				return nil, fmt.Errorf("%w: %w", failErr, err)
+			}
+		}
+
+		results = append(results, entries...)
+		if len(entries) == 0 || page >= meta.Pagination.LastPage {
+			return results, nil
+		}
+	}
+}
+
+func (h *HetznerRepo) getZoneIDFromDomain(ctx context.Context, domain string) (string, error) {
+	req, err := h.newRequest(ctx, "GET", "/zones", nil)
+	if err != nil {
+		return "", err
+	}
+
+	q := req.URL.Query()
+	q.Add("name", domain)
+	req.URL.RawQuery = q.Encode()
+
+	data, err := h.do(req, "get zone id", ErrGetZoneIDFailes)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return "", ErrDomainNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var zones struct {
+		Zones []hetznerDnsZone `json:"zones"`
+	}
+
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrGetZoneIDFailes, err)
+	}
+
+	for _, zone := range zones.Zones {
+		if zone.Name == domain {
+			return zone.ID, nil
+		}
+	}
+
+	return "", ErrDomainNotFound
+}
+
+// ListZones returns the names of all zones of the account.
+func (h *HetznerRepo) ListZones(ctx context.Context) ([]string, error) {
+	zones, err := hetznerPaginate[hetznerDnsZone](ctx, h, "/zones", "zones", url.Values{}, "list zones", ErrListZonesFailed)
+	if err != nil {
+		return nil, err
+	}
+	return utils.Map(zones, func(zone hetznerDnsZone) string { return zone.Name }), nil
+}
+
+func (h *HetznerRepo) listRecords(ctx context.Context, zoneID string, name string, type_ string) ([]hetznerDnsRecord, error) {
+	// The Hetzner API can only filter by zone, so name and type are matched here.
+	relativeName := ""
+	if name != "" {
+		var err error
+		if relativeName, err = h.toRelative(name); err != nil {
+			return nil, err
+		}
+	}
+
+	dnsRecords, err := hetznerPaginate[hetznerDnsRecord](ctx, h, "/records", "records", url.Values{"zone_id": {zoneID}}, "list records", ErrListRecordsFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]hetznerDnsRecord, 0, len(dnsRecords))
+	for _, record := range dnsRecords {
+		if relativeName != "" && !strings.EqualFold(record.Name, relativeName) {
+			continue
+		}
+		if type_ != "" && record.Type != type_ {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (h *HetznerRepo) ListRecords(ctx context.Context, types ...string) (DnsRecordList, error) {
+	if err := ValidateRecordTypes(types...); err != nil {
+		return nil, err
+	}
+
+	zoneID, err := h.getZoneIDFromDomain(ctx, h.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	dnsRecords, err := h.listRecords(ctx, zoneID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(types) == 0 {
+		types = SupportedRecordTypes
+	}
+
+	records := make([]DnsRecord, 0)
+	for _, record := range dnsRecords {
+		if !slices.Contains(types, record.Type) {
+			continue
+		}
+		records = append(records, h.toDnsRecord(record))
+	}
+
+	return sortDnsRecords(records), nil
+}
+
+func (h *HetznerRepo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	zoneID, err := h.getZoneIDFromDomain(ctx, h.domain)
+	if err != nil {
+		return err
+	}
+
+	name, err := h.toRelative(record.Name)
+	if err != nil {
+		return err
+	}
+	value, err := toHetznerValue(record)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&hetznerDnsRecord{
+		ZoneID: zoneID,
+		Name:   name,
+		Type:   record.Type,
+		Value:  value,
+		TTL:    record.TTL,
+	}); err != nil {
+		return err
+	}
+
+	req, err := h.newRequest(ctx, "POST", "/records", &buf)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.do(req, "create record", ErrCreateRecordFailed)
+	return err
+}
+
+func (h *HetznerRepo) deleteSingleRecord(ctx context.Context, recordID string) error {
+	req, err := h.newRequest(ctx, "DELETE", fmt.Sprintf("/records/%s", recordID), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.do(req, "delete record", ErrDeleteRecordFailed)
+	return err
+}
+
+func (h *HetznerRepo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
+	zoneID, err := h.getZoneIDFromDomain(ctx, h.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := h.listRecords(ctx, zoneID, record.Name, record.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	records = utils.Filter(records, func(r hetznerDnsRecord) bool {
+		return h.toDnsRecord(r).matchesFilter(record)
+	})
+	if len(records) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	successFullDeleted := make([]DnsRecord, 0)
+	errs := make(deleteErrList, 0)
+
+	for _, record := range records {
+		if err := h.deleteSingleRecord(ctx, record.ID); err != nil {
+			errs = append(errs, deleteErr{
+				record: h.toDnsRecord(record),
+				err:    err,
+			})
+			continue
+		}
+		successFullDeleted = append(successFullDeleted, h.toDnsRecord(record))
+	}
+
+	if len(errs) > 0 {
+		return sortDnsRecords(successFullDeleted), errs
+	}
+
+	return sortDnsRecords(successFullDeleted), nil
+}
+
+func (h *HetznerRepo) UpdateRecord(ctx context.Context, record DnsRecord) error {
+	zoneID, err := h.getZoneIDFromDomain(ctx, h.domain)
+	if err != nil {
+		return err
+	}
+
+	records, err := h.listRecords(ctx, zoneID, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+
+	idx, err := selectRecord(utils.Map(records, h.toDnsRecord), record)
+	if err != nil {
+		return err
+	}
+	existing := records[idx]
+
+	record = mergeRecord(h.toDnsRecord(existing), record)
+	value, err := toHetznerValue(record)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&hetznerDnsRecord{
+		ZoneID: zoneID,
+		Name:   existing.Name,
+		Type:   existing.Type,
+		Value:  value,
+		TTL:    record.TTL,
+	}); err != nil {
+		return err
+	}
+
+	req, err := h.newRequest(ctx, "PUT", fmt.Sprintf("/records/%s", existing.ID), &buf)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.do(req, "update record", ErrUpdateRecordFailed)
+	return err
+}
@@ -0,0 +1,186 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/akatranlp/akatran/internal/utils"
+)
+
+const MemoryProvider string = "memory"
+
+// MemoryRepo keeps all records in memory. If a path is given the records are
+// loaded from and persisted to a JSON file after every change.
+type MemoryRepo struct {
+	domain string
+	path   string
+
+	mu      sync.Mutex
+	records []DnsRecord
+	nextID  int
+
+	// OnDelete is called before a record is deleted. Returning an error keeps the
+	// record and reports it as failed, which allows simulating partial failures.
+	OnDelete func(record DnsRecord) error
+}
+
+func NewMemoryRepo(domain, path string) (*MemoryRepo, error) {
+	m := &MemoryRepo{
+		domain:  domain,
+		path:    path,
+		records: make([]DnsRecord, 0),
+	}
+
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &m.records); err != nil {
+		return nil, fmt.Errorf("failed to load records from %s: %w", path, err)
+	}
+	for _, record := range m.records {
+		if id, err := strconv.Atoi(record.ID); err == nil {
+			m.nextID = max(m.nextID, id)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *MemoryRepo) save() error {
+	if m.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(m.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(m.path, data, 0o600)
+}
+
+func (m *MemoryRepo) listRecords(name string, type_ string) []DnsRecord {
+	records := make([]DnsRecord, 0)
+	for _, record := range m.records {
+		if name != "" && !strings.EqualFold(record.Name, name) {
+			continue
+		}
+		if type_ != "" && record.Type != type_ {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func (m *MemoryRepo) ListRecords(ctx context.Context, types ...string) (DnsRecordList, error) {
+	if err := ValidateRecordTypes(types...); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(types) == 0 {
+		types = SupportedRecordTypes
+	}
+
+	records := make([]DnsRecord, 0)
+	for _, record := range m.records {
+		if !slices.Contains(types, record.Type) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return sortDnsRecords(records), nil
+}
+
+func (m *MemoryRepo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	record.ID = strconv.Itoa(m.nextID)
+	record.set = 0
+	m.records = append(m.records, record)
+
+	return m.save()
+}
+
+func (m *MemoryRepo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := utils.Filter(m.listRecords(record.Name, record.Type), func(r DnsRecord) bool {
+		return r.matchesFilter(record)
+	})
+	if len(records) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	successFullDeleted := make([]DnsRecord, 0)
+	errs := make(deleteErrList, 0)
+
+	for _, record := range records {
+		if m.OnDelete != nil {
+			if err := m.OnDelete(record); err != nil {
+				errs = append(errs, deleteErr{
+					record: record,
+					err:    err,
+				})
+				continue
+			}
+		}
+		m.records = slices.DeleteFunc(m.records, func(r DnsRecord) bool {
+			return r.ID == record.ID
+		})
+		successFullDeleted = append(successFullDeleted, record)
+	}
+
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return sortDnsRecords(successFullDeleted), errs
+	}
+
+	return sortDnsRecords(successFullDeleted), nil
+}
+
+func (m *MemoryRepo) UpdateRecord(ctx context.Context, record DnsRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := m.listRecords(record.Name, record.Type)
+	idx, err := selectRecord(records, record)
+	if err != nil {
+		return err
+	}
+
+	for i := range m.records {
+		if m.records[i].ID == records[idx].ID {
+			m.records[i] = mergeRecord(m.records[i], record)
+			m.records[i].ID = records[idx].ID
+		}
+	}
+
+	return m.save()
+}
@@ -0,0 +1,428 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/akatranlp/akatran/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// OwnershipMarker only updates and deletes records whose comment starts with the marker.
+	OwnershipMarker = "marker"
+	// OwnershipAll treats every record of the zone as managed by the spec.
+	OwnershipAll = "all"
+
+	DefaultManagedMarker = "managed by akatran"
+)
+
+var ErrZoneChanged = errors.New("the zone changed since the plan was created")
+
+// ZoneSpec is the desired state of a zone as kept in a YAML file.
+type ZoneSpec struct {
+	Zone      string      `yaml:"zone"`
+	Ownership string      `yaml:"ownership"`
+	Marker    string      `yaml:"marker"`
+	Records   []DnsRecord `yaml:"records"`
+
+	// exact compares the comment and tags of the records exactly, so comments and
+	// tags that the records of the spec do not have are removed.
+	exact bool
+}
+
+// LoadZoneSpec reads the spec from path. Record names are relative to the zone
+// unless they end with the zone or a dot, "@" is the zone itself.
+func LoadZoneSpec(path string) (*ZoneSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec ZoneSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	spec.Zone = strings.ToLower(strings.TrimSuffix(spec.Zone, "."))
+	if spec.Zone == "" {
+		return nil, fmt.Errorf("%s: zone is required", path)
+	}
+
+	switch spec.Ownership {
+	case "":
+		spec.Ownership = OwnershipMarker
+	case OwnershipMarker, OwnershipAll:
+	default:
+		return nil, fmt.Errorf("%s: invalid ownership %q, valid are %s and %s", path, spec.Ownership, OwnershipMarker, OwnershipAll)
+	}
+	if spec.Marker == "" {
+		spec.Marker = DefaultManagedMarker
+	}
+
+	for i, record := range spec.Records {
+		if record.ID != "" {
+			return nil, fmt.Errorf("%s: record %d: the id is assigned by the provider and can not be set", path, i+1)
+		}
+		record.Name = spec.absoluteName(record.Name)
+		if spec.Ownership == OwnershipMarker {
+			record.Comment = spec.managedComment(record.Comment)
+		}
+
+		record, err := NormalizeRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", path, i+1, err)
+		}
+
+		for _, other := range spec.Records[:i] {
+			if sameRecord(record, other) {
+				return nil, fmt.Errorf("%s: record %d: duplicate %s record %s %s", path, i+1, record.Type, record.Name, record.Content)
+			}
+		}
+		spec.Records[i] = record
+	}
+
+	return &spec, nil
+}
+
+func (s *ZoneSpec) absoluteName(name string) string {
+	switch {
+	case name == "" || name == "@":
+		return s.Zone
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case isInZone(strings.ToLower(name), s.Zone):
+		return name
+	default:
+		return name + "." + s.Zone
+	}
+}
+
+func (s *ZoneSpec) managedComment(comment string) string {
+	if comment == "" {
+		return s.Marker
+	}
+	return s.Marker + ": " + comment
+}
+
+func (s *ZoneSpec) isManaged(record DnsRecord) bool {
+	return s.Ownership == OwnershipAll || strings.HasPrefix(record.Comment, s.Marker)
+}
+
+// sameRecord reports whether both records are the same resource record, ignoring
+// the attributes that can be updated in place like TTL or comment.
+func sameRecord(a, b DnsRecord) bool {
+	return strings.EqualFold(a.Name, b.Name) &&
+		a.Type == b.Type &&
+		a.matchesContent(b.Content) &&
+		a.Priority == b.Priority &&
+		a.Weight == b.Weight &&
+		a.Port == b.Port &&
+		a.Flags == b.Flags &&
+		a.Tag == b.Tag
+}
+
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+type Change struct {
+	Action ChangeAction `json:"action"`
+	Before *DnsRecord   `json:"before,omitempty"`
+	After  *DnsRecord   `json:"after,omitempty"`
+	// Exact updates replace the record with After as a whole, so they also remove the
+	// comment and tags that After does not have.
+	Exact bool `json:"exact,omitempty"`
+}
+
+type Plan struct {
+	Zone string `json:"zone"`
+	// Fingerprint identifies the records of the zone the plan was computed against.
+	Fingerprint string   `json:"fingerprint"`
+	Changes     []Change `json:"changes"`
+}
+
+// Fingerprint returns a hash over all records of a zone.
+func Fingerprint(records DnsRecordList) string {
+	data, _ := json.Marshal(sortDnsRecords(slices.Clone(records)))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func recordGroupKey(record DnsRecord) string {
+	return strings.ToLower(record.Name) + " " + record.Type
+}
+
+// attributesDiffer reports whether the attributes set in want differ from the current record.
+// With exact the comment and tags of want are compared even if they are empty.
+func attributesDiffer(current, want DnsRecord, exact bool) bool {
+	return (want.TTL != 0 && want.TTL != current.TTL) ||
+		(want.Proxied != nil && (current.Proxied == nil || *current.Proxied != *want.Proxied)) ||
+		((want.Comment != "" || exact) && want.Comment != current.Comment) ||
+		((want.Tags != nil || exact) && !slices.Equal(want.Tags, current.Tags))
+}
+
+// NewPlan computes the changes needed to make the zone of repo match the spec.
+// Records that are not managed are never updated or deleted.
+func NewPlan(ctx context.Context, repo DnsRepository, spec *ZoneSpec) (*Plan, error) {
+	current, err := repo.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0)
+	currentGroups := make(map[string][]DnsRecord)
+	desiredGroups := make(map[string][]DnsRecord)
+	for _, record := range sortDnsRecords(append(slices.Clone(current), spec.Records...)) {
+		if key := recordGroupKey(record); !slices.Contains(groups, key) {
+			groups = append(groups, key)
+		}
+	}
+	for _, record := range current {
+		currentGroups[recordGroupKey(record)] = append(currentGroups[recordGroupKey(record)], record)
+	}
+	for _, record := range spec.Records {
+		desiredGroups[recordGroupKey(record)] = append(desiredGroups[recordGroupKey(record)], record)
+	}
+
+	creates, updates, deletes := make([]Change, 0), make([]Change, 0), make([]Change, 0)
+	for _, key := range groups {
+		existing := currentGroups[key]
+		used := make([]bool, len(existing))
+
+		missing := make([]DnsRecord, 0)
+		for _, want := range desiredGroups[key] {
+			idx := slices.IndexFunc(existing, func(record DnsRecord) bool {
+				return sameRecord(record, want)
+			})
+			if idx < 0 {
+				missing = append(missing, want)
+				continue
+			}
+
+			used[idx] = true
+			if spec.isManaged(existing[idx]) && attributesDiffer(existing[idx], want, spec.exact) {
+				updates = append(updates, Change{Action: ChangeUpdate, Before: &existing[idx], After: &want, Exact: spec.exact})
+			}
+		}
+
+		leftover := make([]DnsRecord, 0)
+		for i, record := range existing {
+			if !used[i] && spec.isManaged(record) {
+				leftover = append(leftover, record)
+			}
+		}
+
+		// A single record whose content changed is updated in place.
+		if len(existing) == 1 && len(leftover) == 1 && len(missing) == 1 {
+			updates = append(updates, Change{Action: ChangeUpdate, Before: &leftover[0], After: &missing[0], Exact: spec.exact})
+			continue
+		}
+
+		for i := range missing {
+			creates = append(creates, Change{Action: ChangeCreate, After: &missing[i]})
+		}
+		for i := range leftover {
+			deletes = append(deletes, Change{Action: ChangeDelete, Before: &leftover[i]})
+		}
+	}
+
+	// Deletes go first, so a CNAME can replace other records of the same name.
+	changes := slices.Concat(deletes, updates, creates)
+	return &Plan{
+		Zone:        spec.Zone,
+		Fingerprint: Fingerprint(current),
+		Changes:     changes,
+	}, nil
+}
+
+// LoadPlan reads a plan saved with Plan.Save.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to load plan from %s: %w", path, err)
+	}
+	return &plan, nil
+}
+
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, data, 0o644)
+}
+
+// Verify fails with ErrZoneChanged if the records of the zone differ from the
+// records the plan was computed against.
+func (p *Plan) Verify(ctx context.Context, repo DnsRepository) error {
+	current, err := repo.ListRecords(ctx)
+	if err != nil {
+		return err
+	}
+	if Fingerprint(current) != p.Fingerprint {
+		return ErrZoneChanged
+	}
+	return nil
+}
+
+// operation returns the provider requests of the change.
+func (c Change) operation(repo DnsRepository) RecordOperation {
+	op := RecordOperation{Action: c.Action}
+	switch {
+	case c.Action == ChangeCreate:
+		op.Record = *c.After
+		op.Run = func(ctx context.Context) error { return repo.CreateRecord(ctx, *c.After) }
+	case c.Action == ChangeDelete:
+		op.Record = *c.Before
+		op.Run = func(ctx context.Context) error {
+			_, err := repo.DeleteRecord(ctx, *c.Before)
+			return err
+		}
+	case c.Action == ChangeUpdate:
+		// The ID selects the record even if others share its name and type.
+		update := *c.After
+		update.ID = c.Before.ID
+		if c.Exact {
+			update = update.WithFields(allRecordFields)
+		}
+		op.Record = mergeRecord(*c.Before, update)
+		op.Run = func(ctx context.Context) error { return repo.UpdateRecord(ctx, update) }
+	default:
+		op.Run = func(ctx context.Context) error { return fmt.Errorf("unknown action %q", c.Action) }
+	}
+	return op
+}
+
+// Apply executes the changes with at most concurrency requests at the same time. All
+// deletes finish before the updates and the updates before the creates start. If a
+// stage has a failure, the changes of the later stages are skipped.
+func (p *Plan) Apply(ctx context.Context, repo DnsRepository, concurrency int) RecordResultList {
+	ops := make([]RecordOperation, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		ops = append(ops, change.operation(repo))
+	}
+	return runStagedOperations(ctx, concurrency, ops)
+}
+
+func (p *Plan) counts() (creates, updates, deletes int) {
+	for _, change := range p.Changes {
+		switch change.Action {
+		case ChangeCreate:
+			creates++
+		case ChangeUpdate:
+			updates++
+		case ChangeDelete:
+			deletes++
+		}
+	}
+	return creates, updates, deletes
+}
+
+// String renders the plan as a readable diff.
+func (p *Plan) String() string {
+	if len(p.Changes) == 0 {
+		return fmt.Sprintf("No changes. The zone %s is up to date.", p.Zone)
+	}
+
+	var sb strings.Builder
+	for _, change := range p.Changes {
+		switch change.Action {
+		case ChangeCreate:
+			fmt.Fprintf(&sb, "+ %s\n", describeRecord(*change.After))
+		case ChangeDelete:
+			fmt.Fprintf(&sb, "- %s\n", describeRecord(*change.Before))
+		case ChangeUpdate:
+			fmt.Fprintf(&sb, "~ %s %s\n", change.Before.Type, change.Before.Name)
+			for _, diff := range attributeDiffs(*change.Before, *change.After, change.Exact) {
+				fmt.Fprintf(&sb, "    %s\n", diff)
+			}
+		}
+	}
+
+	creates, updates, deletes := p.counts()
+	fmt.Fprintf(&sb, "\nPlan: %d to create, %d to update, %d to delete.", creates, updates, deletes)
+	return sb.String()
+}
+
+func describeRecord(record DnsRecord) string {
+	parts := []string{record.Type, record.Name}
+	switch record.Type {
+	case "MX":
+		parts = append(parts, strconv.Itoa(int(record.Priority)))
+	case "SRV":
+		parts = append(parts, strconv.Itoa(int(record.Priority)), strconv.Itoa(int(record.Weight)), strconv.Itoa(int(record.Port)))
+	case "CAA":
+		parts = append(parts, strconv.Itoa(int(record.Flags)), record.Tag)
+	}
+	parts = append(parts, record.Content)
+
+	if record.TTL != 0 {
+		parts = append(parts, fmt.Sprintf("ttl=%d", record.TTL))
+	}
+	if record.Proxied != nil {
+		parts = append(parts, fmt.Sprintf("proxied=%t", *record.Proxied))
+	}
+	if record.Comment != "" {
+		parts = append(parts, fmt.Sprintf("comment=%q", record.Comment))
+	}
+	if len(record.Tags) > 0 {
+		parts = append(parts, "tags="+strings.Join(record.Tags, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+func attributeDiffs(before, after DnsRecord, exact bool) []string {
+	diffs := make([]string, 0)
+	add := func(name, from, to string) {
+		if from != to {
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", name, from, to))
+		}
+	}
+	proxied := func(p *bool) string {
+		if p == nil {
+			return "-"
+		}
+		return strconv.FormatBool(*p)
+	}
+
+	add("content", before.Content, after.Content)
+	add("priority", strconv.Itoa(int(before.Priority)), strconv.Itoa(int(after.Priority)))
+	add("weight", strconv.Itoa(int(before.Weight)), strconv.Itoa(int(after.Weight)))
+	add("port", strconv.Itoa(int(before.Port)), strconv.Itoa(int(after.Port)))
+	add("flags", strconv.Itoa(int(before.Flags)), strconv.Itoa(int(after.Flags)))
+	add("tag", before.Tag, after.Tag)
+	if after.TTL != 0 {
+		add("ttl", strconv.Itoa(int(before.TTL)), strconv.Itoa(int(after.TTL)))
+	}
+	if after.Proxied != nil {
+		add("proxied", proxied(before.Proxied), proxied(after.Proxied))
+	}
+	if after.Comment != "" || exact {
+		add("comment", strconv.Quote(before.Comment), strconv.Quote(after.Comment))
+	}
+	if after.Tags != nil || exact {
+		add("tags", strings.Join(before.Tags, ","), strings.Join(after.Tags, ","))
+	}
+	return diffs
+}
@@ -0,0 +1,234 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// planSummary describes the changes as action, type, name and content for comparisons.
+func planSummary(plan *Plan) []string {
+	summary := make([]string, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		record := change.After
+		if record == nil {
+			record = change.Before
+		}
+		summary = append(summary, string(change.Action)+" "+record.Type+" "+record.Name+" "+record.Content)
+	}
+	return summary
+}
+
+func TestNewPlan(t *testing.T) {
+	const managed = DefaultManagedMarker
+
+	tests := []struct {
+		name      string
+		current   []DnsRecord
+		ownership string
+		records   []DnsRecord
+		want      []string
+	}{
+		{
+			name:    "create missing records",
+			records: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: managed}},
+			want:    []string{"create A www.example.com 192.0.2.1"},
+		},
+		{
+			name:    "nothing to do",
+			current: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300, Comment: managed}},
+			records: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300, Comment: managed}},
+			want:    []string{},
+		},
+		{
+			name:    "update the ttl",
+			current: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300, Comment: managed}},
+			records: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 600, Comment: managed}},
+			want:    []string{"update A www.example.com 192.0.2.1"},
+		},
+		{
+			name:    "update the content of a single record in place",
+			current: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: managed}},
+			records: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.2", Comment: managed}},
+			want:    []string{"update A www.example.com 192.0.2.2"},
+		},
+		{
+			name: "replace one of several values",
+			current: []DnsRecord{
+				{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: managed},
+				{Name: "www.example.com", Type: "A", Content: "192.0.2.2", Comment: managed},
+			},
+			records: []DnsRecord{
+				{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: managed},
+				{Name: "www.example.com", Type: "A", Content: "192.0.2.3", Comment: managed},
+			},
+			want: []string{"delete A www.example.com 192.0.2.2", "create A www.example.com 192.0.2.3"},
+		},
+		{
+			name:    "delete managed records",
+			current: []DnsRecord{{Name: "old.example.com", Type: "A", Content: "192.0.2.9", Comment: managed}},
+			want:    []string{"delete A old.example.com 192.0.2.9"},
+		},
+		{
+			name: "keep unmanaged records",
+			current: []DnsRecord{
+				{Name: "old.example.com", Type: "A", Content: "192.0.2.9"},
+				{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+			},
+			records: []DnsRecord{{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 600, Comment: managed}},
+			want:    []string{},
+		},
+		{
+			name:      "ownership all deletes unmanaged records",
+			ownership: OwnershipAll,
+			current:   []DnsRecord{{Name: "old.example.com", Type: "A", Content: "192.0.2.9"}},
+			want:      []string{"delete A old.example.com 192.0.2.9"},
+		},
+		{
+			name: "deletes come first",
+			current: []DnsRecord{
+				{Name: "app.example.com", Type: "A", Content: "192.0.2.1", Comment: managed},
+				{Name: "db.example.com", Type: "A", Content: "192.0.2.5", TTL: 300, Comment: managed},
+			},
+			records: []DnsRecord{
+				{Name: "app.example.com", Type: "CNAME", Content: "www.example.com", Comment: managed},
+				{Name: "db.example.com", Type: "A", Content: "192.0.2.5", TTL: 600, Comment: managed},
+			},
+			want: []string{"delete A app.example.com 192.0.2.1", "update A db.example.com 192.0.2.5", "create CNAME app.example.com www.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestMemoryRepo(t, tt.current...)
+			ownership := tt.ownership
+			if ownership == "" {
+				ownership = OwnershipMarker
+			}
+			spec := &ZoneSpec{Zone: "example.com", Ownership: ownership, Marker: managed, Records: tt.records}
+
+			plan, err := NewPlan(context.Background(), repo, spec)
+			if err != nil {
+				t.Fatalf("NewPlan() error = %v", err)
+			}
+			if got := planSummary(plan); !slices.Equal(got, tt.want) {
+				t.Errorf("NewPlan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t,
+		DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: DefaultManagedMarker},
+		DnsRecord{Name: "old.example.com", Type: "A", Content: "192.0.2.9", Comment: DefaultManagedMarker},
+	)
+	before, err := repo.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wwwID := before[slices.IndexFunc(before, func(r DnsRecord) bool { return r.Name == "www.example.com" })].ID
+
+	spec := &ZoneSpec{Zone: "example.com", Ownership: OwnershipMarker, Marker: DefaultManagedMarker, Records: []DnsRecord{
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.2", TTL: 300, Comment: DefaultManagedMarker},
+		{Name: "api.example.com", Type: "A", Content: "192.0.2.3", Comment: DefaultManagedMarker},
+	}}
+	plan, err := NewPlan(ctx, repo, spec)
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+
+	// The plan is saved and loaded like between dns plan and dns apply.
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := plan.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if plan, err = LoadPlan(path); err != nil {
+		t.Fatalf("LoadPlan() error = %v", err)
+	}
+	if err := plan.Verify(ctx, repo); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := plan.Apply(ctx, repo, 2).Err(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if err := plan.Verify(ctx, repo); err == nil {
+		t.Errorf("Verify() after Apply() error = nil, want %v", ErrZoneChanged)
+	}
+
+	again, err := NewPlan(ctx, repo, spec)
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("NewPlan() after Apply() = %q, want no changes", planSummary(again))
+	}
+
+	www, err := FindRecords(ctx, repo, DnsRecord{Name: "www.example.com", Type: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(www) != 1 || www[0].ID != wwwID {
+		t.Errorf("www records = %+v, want the record with id %s updated in place", www, wwwID)
+	}
+}
+
+func TestLoadZoneSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "relative names and marker comments",
+			content: "zone: example.com.\nrecords:\n  - {name: www, type: A, content: 192.0.2.1}\n  - {name: '@', type: MX, content: mx.example.com, priority: 10, comment: mail}\n  - {name: api.example.com, type: A, content: 192.0.2.2}\n  - {name: other.example.net., type: A, content: 192.0.2.3}\n",
+			want: []string{
+				"www.example.com A 192.0.2.1 managed by akatran",
+				"example.com MX mx.example.com managed by akatran: mail",
+				"api.example.com A 192.0.2.2 managed by akatran",
+				"other.example.net A 192.0.2.3 managed by akatran",
+			},
+		},
+		{
+			name:    "ownership all keeps comments",
+			content: "zone: example.com\nownership: all\nrecords:\n  - {name: www, type: A, content: 192.0.2.1}\n",
+			want:    []string{"www.example.com A 192.0.2.1 "},
+		},
+		{name: "missing zone", content: "records: []\n", wantErr: true},
+		{name: "invalid ownership", content: "zone: example.com\nownership: some\n", wantErr: true},
+		{name: "unknown field", content: "zone: example.com\nrecordz: []\n", wantErr: true},
+		{name: "id set", content: "zone: example.com\nrecords:\n  - {id: '1', name: www, type: A, content: 192.0.2.1}\n", wantErr: true},
+		{name: "invalid record", content: "zone: example.com\nrecords:\n  - {name: www, type: A, content: not-an-address}\n", wantErr: true},
+		{name: "duplicate record", content: "zone: example.com\nrecords:\n  - {name: www, type: A, content: 192.0.2.1}\n  - {name: WWW, type: A, content: 192.0.2.1}\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "zone.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			spec, err := LoadZoneSpec(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadZoneSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := make([]string, 0, len(spec.Records))
+			for _, record := range spec.Records {
+				got = append(got, record.Name+" "+record.Type+" "+record.Content+" "+record.Comment)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LoadZoneSpec() records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/akatran/internal/viper"
+	"github.com/miekg/dns"
+)
+
+const (
+	DefaultPropagationTimeout  = 5 * time.Minute
+	defaultPropagationInterval = 5 * time.Second
+	propagationQueryTimeout    = 5 * time.Second
+)
+
+type PropagationResult struct {
+	Server string `json:"server"`
+	// Authoritative is false for public resolvers, which may still serve cached values.
+	Authoritative bool     `json:"authoritative"`
+	Values        []string `json:"values"`
+	Matches       bool     `json:"matches"`
+	Error         string   `json:"error,omitempty"`
+}
+
+type PropagationResultList []PropagationResult
+
+// Propagated reports whether all authoritative servers serve the expected values.
+func (p PropagationResultList) Propagated() bool {
+	for _, result := range p {
+		if result.Authoritative && !result.Matches {
+			return false
+		}
+	}
+	return true
+}
+
+func (p PropagationResultList) AsTableString() string {
+	return renderTable(p, []tableColumn[PropagationResult]{
+		{header: "SERVER", value: func(r PropagationResult) string { return r.Server }},
+		{header: "KIND", value: func(r PropagationResult) string {
+			if r.Authoritative {
+				return "authoritative"
+			}
+			return "resolver"
+		}},
+		{header: "VALUES", value: func(r PropagationResult) string { return strings.Join(r.Values, ", ") }},
+		{header: "STATUS", value: func(r PropagationResult) string {
+			switch {
+			case r.Error != "":
+				return "error: " + r.Error
+			case r.Matches:
+				return "ok"
+			default:
+				return "pending"
+			}
+		}},
+	})
+}
+
+func (p PropagationResultList) AsJsonString() string {
+	var builder strings.Builder
+	json.NewEncoder(&builder).Encode(p)
+
+	return builder.String()
+}
+
+// PropagationChecker queries name servers directly to see whether a change is live.
+type PropagationChecker struct {
+	// Nameservers overrides the authoritative name servers found by a NS lookup of the zone.
+	Nameservers []string
+	// Resolvers are public resolvers that are queried in addition, e.g. 1.1.1.1.
+	Resolvers []string
+
+	client *dns.Client
+	// lookupNS looks up the name servers of the zone, net.DefaultResolver.LookupNS if it is nil.
+	lookupNS func(ctx context.Context, zone string) ([]*net.NS, error)
+	// interval is the time Wait waits between two checks, defaultPropagationInterval if it is 0.
+	interval time.Duration
+}
+
+// NewPropagationChecker returns a checker with the name servers and resolvers of the
+// config, dns::<zone>::nameservers and propagation::resolvers.
+func NewPropagationChecker(zone string) *PropagationChecker {
+	return &PropagationChecker{
+		Nameservers: viper.GetStringSlice("dns::" + zone + "::nameservers"),
+		Resolvers:   viper.GetStringSlice("propagation::resolvers"),
+		client:      &dns.Client{Timeout: propagationQueryTimeout},
+	}
+}
+
+func withPort(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.TrimSuffix(server, "."), "53")
+}
+
+func (c *PropagationChecker) nameservers(ctx context.Context, zone string) ([]string, error) {
+	if len(c.Nameservers) > 0 {
+		return c.Nameservers, nil
+	}
+
+	lookupNS := c.lookupNS
+	if lookupNS == nil {
+		lookupNS = net.DefaultResolver.LookupNS
+	}
+	records, err := lookupNS(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the name servers of %s: %w", zone, err)
+	}
+
+	servers := make([]string, 0, len(records))
+	for _, record := range records {
+		servers = append(servers, record.Host)
+	}
+	return servers, nil
+}
+
+func (c *PropagationChecker) query(ctx context.Context, server string, authoritative bool, name string, type_ string, expected []string) PropagationResult {
+	result := PropagationResult{
+		Server:        strings.TrimSuffix(server, "."),
+		Authoritative: authoritative,
+		Values:        make([]string, 0),
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.StringToType[type_])
+	msg.RecursionDesired = !authoritative
+
+	res, _, err := c.client.ExchangeContext(ctx, msg, withPort(server))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if res.Rcode != dns.RcodeSuccess && res.Rcode != dns.RcodeNameError {
+		result.Error = dns.RcodeToString[res.Rcode]
+		return result
+	}
+
+	for _, rr := range res.Answer {
+		if rr.Header().Rrtype != msg.Question[0].Qtype {
+			continue
+		}
+		result.Values = append(result.Values, rrToDnsRecord(rr).Content)
+	}
+	slices.Sort(result.Values)
+
+	if len(expected) == 0 {
+		result.Matches = len(result.Values) == 0
+		return result
+	}
+	result.Matches = true
+	for _, value := range expected {
+		if !slices.ContainsFunc(result.Values, func(v string) bool { return DnsRecord{Content: v}.matchesContent(value) }) {
+			result.Matches = false
+		}
+	}
+	return result
+}
+
+// Check queries every authoritative name server of the zone and the configured resolvers
+// for the records of name and type. A server matches if it serves all expected values,
+// or no value at all if nothing is expected.
+func (c *PropagationChecker) Check(ctx context.Context, zone, name, type_ string, expected []string) (PropagationResultList, error) {
+	if err := ValidateRecordTypes(type_); err != nil {
+		return nil, err
+	}
+
+	nameservers, err := c.nameservers(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(PropagationResultList, 0, len(nameservers)+len(c.Resolvers))
+	for _, server := range nameservers {
+		results = append(results, c.query(ctx, server, true, name, type_, expected))
+	}
+	for _, server := range c.Resolvers {
+		results = append(results, c.query(ctx, server, false, name, type_, expected))
+	}
+	return results, nil
+}
+
+// Wait checks the propagation until every authoritative name server serves the expected
+// values or the timeout expires. A failed lookup of the name servers is retried as well,
+// as it is often only a resolver that timed out.
+func (c *PropagationChecker) Wait(ctx context.Context, zone, name, type_ string, expected []string, timeout time.Duration) (PropagationResultList, error) {
+	if err := ValidateRecordTypes(type_); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	checker := *c
+	checker.Resolvers = nil
+	interval := c.interval
+	if interval <= 0 {
+		interval = defaultPropagationInterval
+	}
+
+	for {
+		results, err := checker.Check(ctx, zone, name, type_, expected)
+		if err == nil && results.Propagated() {
+			return results, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return nil, fmt.Errorf("%s record %s was not propagated within %s: %w", type_, name, timeout, err)
+			}
+			return results, fmt.Errorf("%s record %s was not propagated within %s", type_, name, timeout)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// newTestDNSServer serves the answers of answer on a local UDP port and returns its address.
+// answer gets the question and returns the records in zone file format.
+func newTestDNSServer(t *testing.T, answer func(q dns.Question) []string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			res := new(dns.Msg)
+			res.SetReply(req)
+			res.Authoritative = true
+			for _, record := range answer(req.Question[0]) {
+				rr, err := dns.NewRR(record)
+				if err != nil {
+					t.Errorf("invalid answer %q: %v", record, err)
+					continue
+				}
+				res.Answer = append(res.Answer, rr)
+			}
+			w.WriteMsg(res)
+		}),
+	}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+func newTestPropagationChecker(lookupNS func(ctx context.Context, zone string) ([]*net.NS, error), nameservers ...string) *PropagationChecker {
+	return &PropagationChecker{
+		Nameservers: nameservers,
+		client:      &dns.Client{Timeout: time.Second},
+		lookupNS:    lookupNS,
+		interval:    10 * time.Millisecond,
+	}
+}
+
+func TestPropagationCheckerWait(t *testing.T) {
+	var queries atomic.Int32
+	server := newTestDNSServer(t, func(q dns.Question) []string {
+		// The new value is served from the third query on.
+		value := "old"
+		if queries.Add(1) > 2 {
+			value = "new"
+		}
+		return []string{q.Name + ` 300 IN TXT "` + value + `"`}
+	})
+
+	tests := []struct {
+		name     string
+		expected []string
+		lookupNS func(ctx context.Context, zone string) ([]*net.NS, error)
+		timeout  time.Duration
+		wantErr  string
+	}{
+		{name: "propagated", expected: []string{"new"}, timeout: 5 * time.Second},
+		{name: "not propagated by the timeout", expected: []string{"other"}, timeout: 200 * time.Millisecond, wantErr: "TXT record www.example.com was not propagated within 200ms"},
+		{
+			name:     "name server lookup is retried",
+			expected: []string{"new"},
+			lookupNS: func() func(ctx context.Context, zone string) ([]*net.NS, error) {
+				var lookups atomic.Int32
+				return func(ctx context.Context, zone string) ([]*net.NS, error) {
+					if lookups.Add(1) < 3 {
+						return nil, errors.New("i/o timeout")
+					}
+					return []*net.NS{{Host: server}}, nil
+				}
+			}(),
+			timeout: 5 * time.Second,
+		},
+		{
+			name:     "name server lookup fails until the timeout",
+			expected: []string{"new"},
+			lookupNS: func(ctx context.Context, zone string) ([]*net.NS, error) {
+				return nil, errors.New("i/o timeout")
+			},
+			timeout: 200 * time.Millisecond,
+			wantErr: "failed to look up the name servers of example.com: i/o timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries.Store(0)
+			var checker *PropagationChecker
+			if tt.lookupNS != nil {
+				checker = newTestPropagationChecker(tt.lookupNS)
+			} else {
+				checker = newTestPropagationChecker(nil, server)
+			}
+
+			results, err := checker.Wait(context.Background(), "example.com", "www.example.com", "TXT", tt.expected, tt.timeout)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Wait() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				if results != nil && results.Propagated() {
+					t.Errorf("Wait() = %+v, want the results of the last check", results)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Wait() error = %v", err)
+			}
+			if len(results) != 1 || !results.Propagated() || results[0].Values[0] != "new" {
+				t.Errorf("Wait() = %+v, want the name server to serve the new value", results)
+			}
+		})
+	}
+}
+
+func TestPropagationCheckerCheck(t *testing.T) {
+	server := newTestDNSServer(t, func(q dns.Question) []string {
+		if q.Qtype != dns.TypeA {
+			return nil
+		}
+		return []string{q.Name + " 300 IN A 192.0.2.1", q.Name + " 300 IN A 192.0.2.2"}
+	})
+	checker := newTestPropagationChecker(nil, server)
+	checker.Resolvers = []string{server}
+
+	tests := []struct {
+		name     string
+		type_    string
+		expected []string
+		want     bool
+	}{
+		{name: "all values", type_: "A", expected: []string{"192.0.2.2", "192.0.2.1"}, want: true},
+		{name: "one of the values", type_: "A", expected: []string{"192.0.2.1"}, want: true},
+		{name: "missing value", type_: "A", expected: []string{"192.0.2.3"}, want: false},
+		{name: "deleted", type_: "AAAA", want: true},
+		{name: "not deleted", type_: "A", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := checker.Check(context.Background(), "example.com", "www.example.com", tt.type_, tt.expected)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if len(results) != 2 || !results[0].Authoritative || results[1].Authoritative {
+				t.Fatalf("Check() = %+v, want the name server and the resolver", results)
+			}
+			if got := results[0].Matches && results[1].Matches; got != tt.want {
+				t.Errorf("Check() = %+v, want matches %v", results, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrAmbiguousRecord = errors.New("record is ambiguous")
+)
+
+// SupportedRecordTypes lists all record types in the order they are listed.
+var SupportedRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "PTR"}
+
+var caaTags = []string{"issue", "issuewild", "iodef"}
+
+// DefaultCAATag is the tag of new CAA records that do not set one.
+const DefaultCAATag = "issue"
+
+func recordTypeOrder(type_ string) int {
+	if idx := slices.Index(SupportedRecordTypes, type_); idx >= 0 {
+		return idx
+	}
+	return len(SupportedRecordTypes)
+}
+
+func ValidateRecordTypes(types ...string) error {
+	for _, t := range types {
+		if !slices.Contains(SupportedRecordTypes, t) {
+			return fmt.Errorf("invalid record type: %s", t)
+		}
+	}
+	return nil
+}
+
+func isHostname(name string) bool {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return false
+	}
+	_, ok := dns.IsDomainName(name)
+	return ok
+}
+
+// NormalizeRecord validates the content and the type specific fields of the record
+// and removes the trailing dot from host names.
+func NormalizeRecord(record DnsRecord) (DnsRecord, error) {
+	return normalizeRecord(record, false)
+}
+
+// NormalizeUpdate validates a record that is used as update. Unlike NormalizeRecord the
+// port of an SRV and the tag of a CAA record may be missing, as an update keeps the
+// current values of the type specific fields it does not set.
+func NormalizeUpdate(record DnsRecord) (DnsRecord, error) {
+	return normalizeRecord(record, true)
+}
+
+// CompleteRecord sets the defaults of a new record, like the tag of CAA records, and validates it.
+func CompleteRecord(record DnsRecord) (DnsRecord, error) {
+	record.set = 0
+	if record.Type == "CAA" && record.Tag == "" {
+		record.Tag = DefaultCAATag
+	}
+	return NormalizeRecord(record)
+}
+
+func normalizeRecord(record DnsRecord, update bool) (DnsRecord, error) {
+	if !isHostname(record.Name) {
+		return record, fmt.Errorf("invalid record name: %s", record.Name)
+	}
+	record.Name = strings.TrimSuffix(record.Name, ".")
+
+	switch record.Type {
+	case "A":
+		ip := net.ParseIP(record.Content)
+		if ip == nil || ip.To4() == nil {
+			return record, fmt.Errorf("invalid IPv4 address")
+		}
+		record.Content = ip.String()
+	case "AAAA":
+		ip := net.ParseIP(record.Content)
+		if ip == nil || ip.To4() != nil {
+			return record, fmt.Errorf("invalid IPv6 address")
+		}
+		record.Content = ip.String()
+	case "CNAME", "NS", "PTR":
+		if !isHostname(record.Content) {
+			return record, fmt.Errorf("invalid host name for %s record: %s", record.Type, record.Content)
+		}
+		record.Content = strings.TrimSuffix(record.Content, ".")
+	case "MX":
+		// A single dot is the null MX record of RFC 7505.
+		if record.Content != "." && !isHostname(record.Content) {
+			return record, fmt.Errorf("invalid mail server for MX record: %s", record.Content)
+		}
+		if record.Content != "." {
+			record.Content = strings.TrimSuffix(record.Content, ".")
+		}
+	case "TXT":
+		if record.Content == "" {
+			return record, fmt.Errorf("content is required for TXT records")
+		}
+	case "SRV":
+		labels := dns.SplitDomainName(record.Name)
+		if len(labels) < 3 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return record, fmt.Errorf("SRV record name has to look like _service._proto.example.com: %s", record.Name)
+		}
+		if record.Content != "." && !isHostname(record.Content) {
+			return record, fmt.Errorf("invalid target for SRV record: %s", record.Content)
+		}
+		if record.Content != "." {
+			record.Content = strings.TrimSuffix(record.Content, ".")
+		}
+		if record.Port == 0 && !update {
+			return record, fmt.Errorf("port is required for SRV records")
+		}
+	case "CAA":
+		if record.Flags != 0 && record.Flags != 128 {
+			return record, fmt.Errorf("invalid flags for CAA record: %d", record.Flags)
+		}
+		if (record.Tag != "" || !update) && !slices.Contains(caaTags, record.Tag) {
+			return record, fmt.Errorf("invalid tag for CAA record: %q, valid tags are %s", record.Tag, strings.Join(caaTags, ", "))
+		}
+		if record.Content == "" {
+			return record, fmt.Errorf("content is required for CAA records")
+		}
+	default:
+		return record, fmt.Errorf("invalid record type: %s", record.Type)
+	}
+
+	return record, nil
+}
+
+// selectRecord returns the index of the record an update applies to. A record with an ID
+// selects the record with that ID, otherwise name and type have to match exactly one record.
+func selectRecord(records DnsRecordList, record DnsRecord) (int, error) {
+	if record.ID != "" {
+		idx := slices.IndexFunc(records, func(r DnsRecord) bool { return r.ID == record.ID })
+		if idx < 0 {
+			return -1, fmt.Errorf("%w: %s record %s with id %s", ErrRecordNotFound, record.Type, record.Name, record.ID)
+		}
+		return idx, nil
+	}
+
+	switch len(records) {
+	case 0:
+		return -1, fmt.Errorf("%w: %s record %s", ErrRecordNotFound, record.Type, record.Name)
+	case 1:
+		return 0, nil
+	}
+
+	candidates := make([]string, 0, len(records))
+	for _, r := range records {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", r.ID, r.Content))
+	}
+	return -1, fmt.Errorf("%w: %s has %d %s records: %s", ErrAmbiguousRecord, record.Name, len(records), record.Type, strings.Join(candidates, ", "))
+}
+
+// mergeRecord applies an update to an existing record. TTL, proxied state, comment,
+// tags and the type specific fields like the priority of MX records that the update
+// does not set keep their existing values, see DnsRecord.WithFields.
+func mergeRecord(existing, update DnsRecord) DnsRecord {
+	update.Name = existing.Name
+	update.Type = existing.Type
+
+	if !update.sets(FieldPriority) {
+		update.Priority = existing.Priority
+	}
+	if !update.sets(FieldWeight) {
+		update.Weight = existing.Weight
+	}
+	if !update.sets(FieldPort) {
+		update.Port = existing.Port
+	}
+	if !update.sets(FieldFlags) {
+		update.Flags = existing.Flags
+	}
+	if !update.sets(FieldTag) {
+		update.Tag = existing.Tag
+	}
+	if !update.sets(FieldTTL) {
+		update.TTL = existing.TTL
+	}
+	if update.Proxied == nil {
+		update.Proxied = existing.Proxied
+	}
+	if !update.sets(FieldComment) {
+		update.Comment = existing.Comment
+	}
+	if !update.sets(FieldTags) {
+		update.Tags = existing.Tags
+	}
+	update.set = 0
+	return update
+}
+
+// matchesContent reports whether the record has the given content. An empty content matches every record.
+func (r DnsRecord) matchesContent(content string) bool {
+	return content == "" || strings.TrimSuffix(r.Content, ".") == strings.TrimSuffix(content, ".")
+}
+
+// matchesFilter reports whether the record has the ID and content of filter. Empty fields match every record.
+func (r DnsRecord) matchesFilter(filter DnsRecord) bool {
+	return (filter.ID == "" || r.ID == filter.ID) && r.matchesContent(filter.Content)
+}
+
+// HasTag reports whether the record has the given tag. A tag without a value
+// also matches tags in the name:value form with the same name.
+func (r DnsRecord) HasTag(tag string) bool {
+	return slices.ContainsFunc(r.Tags, func(t string) bool {
+		name, _, _ := strings.Cut(t, ":")
+		return t == tag || (!strings.Contains(tag, ":") && name == tag)
+	})
+}
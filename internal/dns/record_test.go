@@ -0,0 +1,71 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeRecord(t *testing.T) {
+	existing := DnsRecord{
+		ID: "1", Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com",
+		Priority: 10, Weight: 5, Port: 5060, TTL: 300, Proxied: boolPtr(false), Comment: "sip", Tags: []string{"env:prod"},
+	}
+
+	tests := []struct {
+		name   string
+		update DnsRecord
+		want   DnsRecord
+	}{
+		{
+			name:   "empty fields keep their values",
+			update: DnsRecord{ID: "1", Content: "sip2.example.com"},
+			want: DnsRecord{
+				ID: "1", Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip2.example.com",
+				Priority: 10, Weight: 5, Port: 5060, TTL: 300, Proxied: boolPtr(false), Comment: "sip", Tags: []string{"env:prod"},
+			},
+		},
+		{
+			name:   "set fields replace their values",
+			update: DnsRecord{ID: "1", Content: "sip.example.com", Priority: 20, TTL: 600, Proxied: boolPtr(true), Comment: "new", Tags: []string{}},
+			want: DnsRecord{
+				ID: "1", Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com",
+				Priority: 20, Weight: 5, Port: 5060, TTL: 600, Proxied: boolPtr(true), Comment: "new", Tags: []string{},
+			},
+		},
+		{
+			name:   "fields set to zero",
+			update: DnsRecord{ID: "1", Content: "sip.example.com"}.WithFields(FieldPriority | FieldWeight | FieldTTL | FieldComment),
+			want: DnsRecord{
+				ID: "1", Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com",
+				Port: 5060, Proxied: boolPtr(false), Tags: []string{"env:prod"},
+			},
+		},
+		{
+			name:   "all fields",
+			update: DnsRecord{ID: "1", Content: "sip.example.com", Port: 5061}.WithFields(allRecordFields),
+			want: DnsRecord{
+				ID: "1", Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com",
+				Port: 5061, Proxied: boolPtr(false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRecord(existing, tt.update); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRecordFlags(t *testing.T) {
+	existing := DnsRecord{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128, Tag: "issue"}
+
+	if got := mergeRecord(existing, DnsRecord{Content: "letsencrypt.org"}); got.Flags != 128 {
+		t.Errorf("mergeRecord() flags = %d, want the flags of 128 to be kept", got.Flags)
+	}
+	if got := mergeRecord(existing, DnsRecord{Content: "letsencrypt.org"}.WithFields(FieldFlags)); got.Flags != 0 {
+		t.Errorf("mergeRecord() flags = %d, want the flags set to 0", got.Flags)
+	}
+}
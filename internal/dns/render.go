@@ -0,0 +1,266 @@
+package dns
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"golang.org/x/text/width"
+	"gopkg.in/yaml.v3"
+)
+
+type OutputFormat string
+
+const (
+	OutputTable    OutputFormat = "table"
+	OutputWide     OutputFormat = "wide"
+	OutputJSON     OutputFormat = "json"
+	OutputJSONL    OutputFormat = "jsonl"
+	OutputYAML     OutputFormat = "yaml"
+	OutputCSV      OutputFormat = "csv"
+	OutputTemplate OutputFormat = "template"
+)
+
+// OutputFormats lists the values the output flag accepts.
+var OutputFormats = []string{"table", "wide", "json", "jsonl", "yaml", "csv", "template=<gotemplate>"}
+
+// contentMaxWidth is the width at which long contents like DKIM keys wrap in tables.
+const contentMaxWidth = 64
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorCyan  = "\033[36m"
+)
+
+type tableColumn[T any] struct {
+	header     string
+	rightAlign bool
+	minWidth   int
+	// maxWidth wraps longer values into several lines, 0 never wraps.
+	maxWidth int
+	value    func(row T) string
+	// color returns the ANSI color of the value, an empty string keeps the default color.
+	color func(row T) string
+}
+
+// runeWidth returns the number of terminal cells the rune takes. Combining marks and
+// format characters take none, wide east asian characters and most emoji take two.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// wrapText splits the text into lines of at most limit cells. Contents like TXT records
+// are split exactly at the limit, because they often have no spaces to break at.
+func wrapText(s string, limit int) []string {
+	if limit <= 0 || displayWidth(s) <= limit {
+		return []string{s}
+	}
+
+	lines := make([]string, 0)
+	var line strings.Builder
+	lineWidth := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if lineWidth+w > limit && lineWidth > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		line.WriteRune(r)
+		lineWidth += w
+	}
+	return append(lines, line.String())
+}
+
+func pad(s string, w int, rightAlign bool, color string) string {
+	padding := strings.Repeat(" ", max(w-displayWidth(s), 0))
+	if color != "" {
+		s = color + s + colorReset
+	}
+	if rightAlign {
+		return padding + s
+	}
+	return s + padding
+}
+
+func renderTable[T any](rows []T, columns []tableColumn[T]) string {
+	return renderColoredTable(rows, columns, false)
+}
+
+// renderColoredTable renders the rows as table. Values wider than the maximum width of
+// their column continue on the next lines of the row.
+func renderColoredTable[T any](rows []T, columns []tableColumn[T], color bool) string {
+	cells := make([][][]string, len(rows))
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = max(displayWidth(column.header), column.minWidth)
+	}
+	for r, row := range rows {
+		cells[r] = make([][]string, len(columns))
+		for i, column := range columns {
+			cells[r][i] = wrapText(column.value(row), column.maxWidth)
+			for _, line := range cells[r][i] {
+				widths[i] = max(widths[i], displayWidth(line))
+			}
+		}
+	}
+
+	spacerLength := 1
+	for _, w := range widths {
+		spacerLength += w + 3
+	}
+	spacer := strings.Repeat("-", spacerLength)
+
+	var builder strings.Builder
+	fmt.Fprintln(&builder, spacer)
+
+	headerColor := ""
+	if color {
+		headerColor = colorBold
+	}
+	for i, column := range columns {
+		fmt.Fprintf(&builder, "| %s ", pad(column.header, widths[i], column.rightAlign, headerColor))
+	}
+	fmt.Fprintln(&builder, "|")
+	fmt.Fprintln(&builder, spacer)
+
+	for r, row := range rows {
+		lines := 1
+		for _, cell := range cells[r] {
+			lines = max(lines, len(cell))
+		}
+
+		for l := 0; l < lines; l++ {
+			for i, column := range columns {
+				value := ""
+				if l < len(cells[r][i]) {
+					value = cells[r][i][l]
+				}
+				valueColor := ""
+				if color && column.color != nil {
+					valueColor = column.color(row)
+				}
+				fmt.Fprintf(&builder, "| %s ", pad(value, widths[i], column.rightAlign, valueColor))
+			}
+			fmt.Fprintln(&builder, "|")
+		}
+	}
+	fmt.Fprintln(&builder, spacer)
+
+	return builder.String()
+}
+
+// Renderer writes lists in the format selected by the output flag.
+type Renderer struct {
+	Format   OutputFormat
+	Template *template.Template
+	// Color enables colors in tables. It should only be set if the output is a terminal.
+	Color bool
+}
+
+// NewRenderer parses the value of the output flag, one of OutputFormats.
+func NewRenderer(output string, color bool) (*Renderer, error) {
+	if text, ok := strings.CutPrefix(output, "template="); ok {
+		tmpl, err := template.New("output").Funcs(template.FuncMap{
+			"join":  strings.Join,
+			"upper": strings.ToUpper,
+			"lower": strings.ToLower,
+		}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+		return &Renderer{Format: OutputTemplate, Template: tmpl}, nil
+	}
+
+	format := OutputFormat(output)
+	if format == OutputTemplate || !slices.Contains(OutputFormats, output) {
+		return nil, fmt.Errorf("invalid output format %q, use one of %s", output, strings.Join(OutputFormats, ", "))
+	}
+	return &Renderer{Format: format, Color: color}, nil
+}
+
+// RenderRecords writes the records in the format of the renderer.
+func (r *Renderer) RenderRecords(w io.Writer, records DnsRecordList) error {
+	return render(w, r, records, records.tableColumns)
+}
+
+func render[T any](w io.Writer, r *Renderer, rows []T, columns func(wide bool) []tableColumn[T]) error {
+	if rows == nil {
+		rows = make([]T, 0)
+	}
+
+	switch r.Format {
+	case OutputTable, OutputWide:
+		_, err := io.WriteString(w, renderColoredTable(rows, columns(r.Format == OutputWide), r.Color))
+		return err
+	case OutputJSON:
+		return json.NewEncoder(w).Encode(rows)
+	case OutputJSONL:
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(rows); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case OutputCSV:
+		writer := csv.NewWriter(w)
+		wide := columns(true)
+		record := make([]string, len(wide))
+		for i, column := range wide {
+			record[i] = strings.ToLower(column.header)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for i, column := range wide {
+				record[i] = column.value(row)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case OutputTemplate:
+		for _, row := range rows {
+			if err := r.Template.Execute(w, row); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q", r.Format)
+}
@@ -4,50 +4,155 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/akatranlp/akatran/internal/utils"
 )
 
 type DnsRecord struct {
-	Name    string `json:"name"`
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	// ID identifies the record at the provider. Providers without record IDs derive it from the content.
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type" yaml:"type"`
+	Content  string `json:"content" yaml:"content"`
+	Priority uint16 `json:"priority" yaml:"priority"`
+	Weight   uint16 `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Port     uint16 `json:"port,omitempty" yaml:"port,omitempty"`
+	Flags    uint8  `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Tag      string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	TTL     uint32   `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Proxied *bool    `json:"proxied,omitempty" yaml:"proxied,omitempty"`
+	Comment string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Tags    []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// set marks the attributes an update sets even if they are empty, like a priority
+	// of 0 or an empty comment. Other empty attributes keep their current values.
+	set RecordField
 }
 
-type DnsRecordList []DnsRecord
+// RecordField is a set of the attributes of a record an update sets.
+type RecordField uint16
+
+const (
+	FieldPriority RecordField = 1 << iota
+	FieldWeight
+	FieldPort
+	FieldFlags
+	FieldTag
+	FieldTTL
+	FieldComment
+	FieldTags
+
+	// allRecordFields makes an update replace the record as a whole, like a restore does.
+	allRecordFields = FieldPriority | FieldWeight | FieldPort | FieldFlags | FieldTag | FieldTTL | FieldComment | FieldTags
+)
 
-func (d DnsRecordList) AsTableString() string {
-	paddingName := 4
-	paddingContent := 7
-	for _, record := range d {
-		paddingName = max(len(record.Name), paddingName)
-		paddingContent = max(len(record.Content), paddingContent)
-	}
+// WithFields returns the record as an update that sets the fields even if they are empty.
+func (r DnsRecord) WithFields(fields RecordField) DnsRecord {
+	r.set |= fields
+	return r
+}
 
-	var builder strings.Builder
+// sets reports whether the record as an update sets the field, because it is not
+// empty or marked with WithFields.
+func (r DnsRecord) sets(field RecordField) bool {
+	if r.set&field != 0 {
+		return true
+	}
+	switch field {
+	case FieldPriority:
+		return r.Priority != 0
+	case FieldWeight:
+		return r.Weight != 0
+	case FieldPort:
+		return r.Port != 0
+	case FieldFlags:
+		return r.Flags != 0
+	case FieldTag:
+		return r.Tag != ""
+	case FieldTTL:
+		return r.TTL != 0
+	case FieldComment:
+		return r.Comment != ""
+	case FieldTags:
+		return r.Tags != nil
+	}
+	return false
+}
 
-	spacer := strings.Repeat("-", 10+5+paddingName+paddingContent)
-	fmt.Fprintln(&builder, spacer)
+type DnsRecordList []DnsRecord
 
-	typeString := "TYPE "
+// ttlString formats the TTL, 0 and 1 are the automatic TTL of the provider.
+func ttlString(ttl uint32) string {
+	if ttl <= 1 {
+		return "auto"
+	}
+	return strconv.FormatUint(uint64(ttl), 10)
+}
 
-	newPaddingName := paddingName - 4
-	nameString := fmt.Sprintf("%sNAME%s", strings.Repeat(" ", int(math.Ceil(float64(newPaddingName)/2))), strings.Repeat(" ", int(math.Floor(float64(newPaddingName)/2))))
+// tableColumns returns the columns of the table. The columns for the type specific
+// fields are only part of the table if a record of the matching type is listed. The
+// wide table has all columns, including ID, proxy state, comment and tags.
+func (d DnsRecordList) tableColumns(wide bool) []tableColumn[DnsRecord] {
+	columns := []tableColumn[DnsRecord]{
+		{header: "TYPE", minWidth: 5, value: func(r DnsRecord) string { return r.Type }, color: func(DnsRecord) string { return colorCyan }},
+		{header: "NAME", value: func(r DnsRecord) string { return r.Name }},
+		{header: "CONTENT", maxWidth: contentMaxWidth, value: func(r DnsRecord) string { return r.Content }},
+	}
+	if wide {
+		columns = append([]tableColumn[DnsRecord]{{header: "ID", value: func(r DnsRecord) string { return r.ID }}}, columns...)
+	}
 
-	newPaddingContent := paddingContent - 7
-	contentString := fmt.Sprintf("%sCONTENT%s", strings.Repeat(" ", int(math.Ceil(float64(newPaddingContent)/2))), strings.Repeat(" ", int(math.Floor(float64(newPaddingContent)/2))))
+	hasType := func(types ...string) bool {
+		return wide || slices.ContainsFunc(d, func(r DnsRecord) bool { return slices.Contains(types, r.Type) })
+	}
+	uintValue := func(types []string, value func(r DnsRecord) uint64) func(r DnsRecord) string {
+		return func(r DnsRecord) string {
+			if !slices.Contains(types, r.Type) {
+				return ""
+			}
+			return strconv.FormatUint(value(r), 10)
+		}
+	}
 
-	fmt.Fprintf(&builder, "| %s | %s | %s |\n", typeString, nameString, contentString)
-	fmt.Fprintln(&builder, spacer)
-	for _, record := range d {
-		fmt.Fprintf(&builder, "| %-5s | %*s | %*s |\n", record.Type, paddingName, record.Name, paddingContent, record.Content)
+	if hasType("MX", "SRV") {
+		columns = append(columns, tableColumn[DnsRecord]{header: "PRIORITY", rightAlign: true, value: uintValue([]string{"MX", "SRV"}, func(r DnsRecord) uint64 { return uint64(r.Priority) })})
+	}
+	if hasType("SRV") {
+		columns = append(columns,
+			tableColumn[DnsRecord]{header: "WEIGHT", rightAlign: true, value: uintValue([]string{"SRV"}, func(r DnsRecord) uint64 { return uint64(r.Weight) })},
+			tableColumn[DnsRecord]{header: "PORT", rightAlign: true, value: uintValue([]string{"SRV"}, func(r DnsRecord) uint64 { return uint64(r.Port) })},
+		)
+	}
+	if hasType("CAA") {
+		columns = append(columns,
+			tableColumn[DnsRecord]{header: "FLAGS", rightAlign: true, value: uintValue([]string{"CAA"}, func(r DnsRecord) uint64 { return uint64(r.Flags) })},
+			tableColumn[DnsRecord]{header: "TAG", value: func(r DnsRecord) string { return r.Tag }},
+		)
+	}
+	columns = append(columns, tableColumn[DnsRecord]{header: "TTL", rightAlign: true, value: func(r DnsRecord) string { return ttlString(r.TTL) }})
+
+	if wide {
+		columns = append(columns,
+			tableColumn[DnsRecord]{header: "PROXIED", value: func(r DnsRecord) string {
+				if r.Proxied == nil {
+					return ""
+				}
+				return strconv.FormatBool(*r.Proxied)
+			}},
+			tableColumn[DnsRecord]{header: "COMMENT", maxWidth: contentMaxWidth, value: func(r DnsRecord) string { return r.Comment }},
+			tableColumn[DnsRecord]{header: "TAGS", value: func(r DnsRecord) string { return strings.Join(r.Tags, ",") }},
+		)
 	}
-	fmt.Fprintln(&builder, spacer)
 
-	return builder.String()
+	return columns
+}
+
+func (d DnsRecordList) AsTableString() string {
+	return renderTable(d, d.tableColumns(false))
 }
 
 func (d DnsRecordList) AsJsonString() string {
@@ -57,10 +162,31 @@ func (d DnsRecordList) AsJsonString() string {
 	return builder.String()
 }
 
+type deleteErr struct {
+	record DnsRecord
+	err    error
+}
+
+type deleteErrList []deleteErr
+
+func (d deleteErrList) Error() string {
+	messages := make([]string, 0, len(d))
+	for _, err := range d {
+		messages = append(messages, fmt.Sprintf("failed to delete record %s: %s", err.record.Name, err.err))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
 type DnsRepository interface {
 	ListRecords(ctx context.Context, types ...string) (DnsRecordList, error)
 	CreateRecord(ctx context.Context, record DnsRecord) error
+	// UpdateRecord updates the record with the ID of record, or the only record with its
+	// name and type if no ID is set. It returns ErrRecordNotFound if no record matches and
+	// ErrAmbiguousRecord if several records match and no ID is set.
 	UpdateRecord(ctx context.Context, record DnsRecord) error
+	// DeleteRecord deletes all records with the name and type of record.
+	// If the ID or content of record is set, only records with that ID or content are deleted.
 	DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error)
 }
 
@@ -80,18 +206,16 @@ func sortDnsRecords(records []DnsRecord) []DnsRecord {
 	})
 
 	slices.SortFunc(sortedRecords, func(a, b SortDnsRecords) int {
-		switch {
-		case a.Type == b.Type:
-			return slices.Compare(a.nameParts, b.nameParts)
-		case a.Type == "A":
-			return -1
-		case b.Type == "A":
-			return 1
-		case a.Type == "AAAA":
-			return -1
-		default:
-			return 1
+		if a.Type != b.Type {
+			return recordTypeOrder(a.Type) - recordTypeOrder(b.Type)
+		}
+		if c := slices.Compare(a.nameParts, b.nameParts); c != 0 {
+			return c
+		}
+		if a.Priority != b.Priority {
+			return int(a.Priority) - int(b.Priority)
 		}
+		return strings.Compare(a.Content, b.Content)
 	})
 
 	return utils.Map(sortedRecords, func(record SortDnsRecords) DnsRecord {
@@ -0,0 +1,177 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of provider requests a bulk operation runs at the same time.
+const DefaultConcurrency = 4
+
+type ResultStatus string
+
+const (
+	ResultSucceeded ResultStatus = "succeeded"
+	ResultFailed    ResultStatus = "failed"
+	// ResultSkipped operations were not started, because the context was cancelled or
+	// an earlier step they depend on failed.
+	ResultSkipped ResultStatus = "skipped"
+)
+
+// RecordResult is the outcome of a single record of a bulk operation.
+type RecordResult struct {
+	Action ChangeAction `json:"action"`
+	Status ResultStatus `json:"status"`
+	Record DnsRecord    `json:"record"`
+	Error  string       `json:"error,omitempty"`
+}
+
+type RecordResultList []RecordResult
+
+// Count returns the number of results with the status.
+func (r RecordResultList) Count(status ResultStatus) int {
+	count := 0
+	for _, result := range r {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary describes how many operations succeeded, failed or were skipped.
+func (r RecordResultList) Summary() string {
+	return fmt.Sprintf("%d succeeded, %d failed, %d skipped", r.Count(ResultSucceeded), r.Count(ResultFailed), r.Count(ResultSkipped))
+}
+
+// Err returns an error if not every operation succeeded.
+func (r RecordResultList) Err() error {
+	if r.Count(ResultSucceeded) == len(r) {
+		return nil
+	}
+	return fmt.Errorf("%d of %d operations did not succeed (%s)", len(r)-r.Count(ResultSucceeded), len(r), r.Summary())
+}
+
+func (r RecordResultList) AsTableString() string {
+	return renderTable(r, []tableColumn[RecordResult]{
+		{header: "ACTION", value: func(r RecordResult) string { return string(r.Action) }},
+		{header: "STATUS", value: func(r RecordResult) string { return string(r.Status) }},
+		{header: "TYPE", minWidth: 5, value: func(r RecordResult) string { return r.Record.Type }},
+		{header: "NAME", value: func(r RecordResult) string { return r.Record.Name }},
+		{header: "CONTENT", value: func(r RecordResult) string { return r.Record.Content }},
+		{header: "ERROR", value: func(r RecordResult) string { return r.Error }},
+	})
+}
+
+func (r RecordResultList) AsJsonString() string {
+	var builder strings.Builder
+	json.NewEncoder(&builder).Encode(r)
+
+	return builder.String()
+}
+
+// RecordOperation is a provider request for a single record of a bulk operation.
+type RecordOperation struct {
+	Action ChangeAction
+	Record DnsRecord
+	Run    func(ctx context.Context) error
+}
+
+// skipOperations reports the operations as skipped with the reason.
+func skipOperations(ops []RecordOperation, reason string) RecordResultList {
+	results := make(RecordResultList, 0, len(ops))
+	for _, op := range ops {
+		results = append(results, RecordResult{Action: op.Action, Status: ResultSkipped, Record: op.Record, Error: reason})
+	}
+	return results
+}
+
+// runStagedOperations runs the deletes first, then the updates and then the creates, so
+// a record that replaces another one of a conflicting type, e.g. a CNAME replacing an A
+// record, is only created once the other is gone. The operations of a stage run
+// concurrently and the later stages are skipped if one of them fails.
+func runStagedOperations(ctx context.Context, concurrency int, ops []RecordOperation) RecordResultList {
+	results := make(RecordResultList, 0, len(ops))
+	failed := false
+
+	for _, action := range []ChangeAction{ChangeDelete, ChangeUpdate, ChangeCreate} {
+		stage := make([]RecordOperation, 0)
+		for _, op := range ops {
+			if op.Action == action {
+				stage = append(stage, op)
+			}
+		}
+
+		if failed {
+			results = append(results, skipOperations(stage, "an earlier change failed")...)
+			continue
+		}
+
+		stageResults := RunOperations(ctx, concurrency, stage)
+		failed = stageResults.Err() != nil
+		results = append(results, stageResults...)
+	}
+
+	return results
+}
+
+// RunOperations runs the operations with at most concurrency requests at the same time
+// and returns their results in the order of ops. Operations that did not start before
+// the context was cancelled are skipped.
+func RunOperations(ctx context.Context, concurrency int, ops []RecordOperation) RecordResultList {
+	concurrency = max(concurrency, 1)
+
+	results := make(RecordResultList, len(ops))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, op := range ops {
+		results[i] = RecordResult{Action: op.Action, Record: op.Record}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Status = ResultSkipped
+			results[i].Error = err.Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := op.Run(ctx); err != nil {
+				results[i].Status = ResultFailed
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Status = ResultSucceeded
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// DeleteRecords deletes the records one by one, selected by their ID, so the result of
+// every record is known even if some of them fail.
+func DeleteRecords(ctx context.Context, repo DnsRepository, records DnsRecordList, concurrency int) RecordResultList {
+	ops := make([]RecordOperation, 0, len(records))
+	for _, record := range records {
+		ops = append(ops, RecordOperation{
+			Action: ChangeDelete,
+			Record: record,
+			Run: func(ctx context.Context) error {
+				_, err := repo.DeleteRecord(ctx, DnsRecord{ID: record.ID, Name: record.Name, Type: record.Type, Content: record.Content})
+				return err
+			},
+		})
+	}
+	return RunOperations(ctx, concurrency, ops)
+}
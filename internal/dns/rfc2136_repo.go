@@ -0,0 +1,237 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/miekg/dns"
+)
+
+const RFC2136Provider string = "rfc2136"
+
+const rfc2136DefaultTTL = 300
+
+var tsigAlgorithms = map[string]string{
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+type RFC2136Repo struct {
+	domain    string
+	server    string
+	keyName   string
+	keySecret string
+	algorithm string
+	client    *dns.Client
+}
+
+func NewRFC2136Repo(domain, server, keyName, keySecret, algorithm string) (*RFC2136Repo, error) {
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	alg, ok := tsigAlgorithms[strings.ToLower(strings.TrimSuffix(algorithm, "."))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tsig algorithm: %s", algorithm)
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	keyName = dns.Fqdn(keyName)
+
+	return &RFC2136Repo{
+		domain:    domain,
+		server:    server,
+		keyName:   keyName,
+		keySecret: keySecret,
+		algorithm: alg,
+		client: &dns.Client{
+			Net:        "tcp",
+			TsigSecret: map[string]string{keyName: keySecret},
+		},
+	}, nil
+}
+
+func (r *RFC2136Repo) sign(msg *dns.Msg) {
+	msg.SetTsig(r.keyName, r.algorithm, 300, time.Now().Unix())
+}
+
+// transferZone fetches all records of the zone with an AXFR request.
+func (r *RFC2136Repo) transferZone(ctx context.Context) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(r.domain))
+	r.sign(msg)
+
+	transfer := &dns.Transfer{
+		TsigSecret: map[string]string{r.keyName: r.keySecret},
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		transfer.DialTimeout = timeout
+		transfer.ReadTimeout = timeout
+	}
+
+	envelopes, err := transfer.In(msg, r.server)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dns.RR, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case envelope, ok := <-envelopes:
+			if !ok {
+				return records, nil
+			}
+			if envelope.Error != nil {
+				return nil, fmt.Errorf("failed to transfer zone: %w", envelope.Error)
+			}
+			records = append(records, envelope.RR...)
+		}
+	}
+}
+
+func (r *RFC2136Repo) listRecords(ctx context.Context, name string, type_ string) ([]dns.RR, error) {
+	zone, err := r.transferZone(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dns.RR, 0)
+	for _, rr := range zone {
+		header := rr.Header()
+		if name != "" && !strings.EqualFold(header.Name, dns.Fqdn(name)) {
+			continue
+		}
+		if type_ != "" && dns.TypeToString[header.Rrtype] != type_ {
+			continue
+		}
+		records = append(records, rr)
+	}
+
+	return records, nil
+}
+
+func (r *RFC2136Repo) update(ctx context.Context, prepare func(msg *dns.Msg)) error {
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(r.domain))
+	prepare(msg)
+	r.sign(msg)
+
+	res, _, err := r.client.ExchangeContext(ctx, msg, r.server)
+	if err != nil {
+		return err
+	}
+
+	if res.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("update rejected by server: %s", dns.RcodeToString[res.Rcode])
+	}
+	return nil
+}
+
+func (r *RFC2136Repo) ListRecords(ctx context.Context, types ...string) (DnsRecordList, error) {
+	if err := ValidateRecordTypes(types...); err != nil {
+		return nil, err
+	}
+
+	zone, err := r.listRecords(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(types) == 0 {
+		types = SupportedRecordTypes
+	}
+
+	records := make([]DnsRecord, 0)
+	for _, rr := range zone {
+		record := rrToIdentifiedRecord(rr)
+		if !slices.Contains(types, record.Type) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return sortDnsRecords(records), nil
+}
+
+func (r *RFC2136Repo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	if record.TTL == 0 {
+		record.TTL = rfc2136DefaultTTL
+	}
+
+	rr, err := dnsRecordToRR(record, record.TTL)
+	if err != nil {
+		return err
+	}
+
+	if err := r.update(ctx, func(msg *dns.Msg) {
+		msg.Insert([]dns.RR{rr})
+	}); err != nil {
+		return fmt.Errorf("failed to create record: %w", err)
+	}
+	return nil
+}
+
+func (r *RFC2136Repo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
+	records, err := r.listRecords(ctx, record.Name, record.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	records = utils.Filter(records, func(rr dns.RR) bool {
+		return rrToIdentifiedRecord(rr).matchesFilter(record)
+	})
+	if len(records) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	// All records are removed within a single update message, so either all or none are deleted.
+	if err := r.update(ctx, func(msg *dns.Msg) {
+		msg.Remove(records)
+	}); err != nil {
+		return nil, deleteErrList(utils.Map(records, func(rr dns.RR) deleteErr {
+			return deleteErr{record: rrToIdentifiedRecord(rr), err: err}
+		}))
+	}
+
+	return sortDnsRecords(utils.Map(records, rrToIdentifiedRecord)), nil
+}
+
+func (r *RFC2136Repo) UpdateRecord(ctx context.Context, record DnsRecord) error {
+	records, err := r.listRecords(ctx, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+
+	idx, err := selectRecord(utils.Map(records, rrToIdentifiedRecord), record)
+	if err != nil {
+		return err
+	}
+
+	old := records[idx]
+	record = mergeRecord(rrToDnsRecord(old), record)
+	if record.TTL == 0 {
+		record.TTL = rfc2136DefaultTTL
+	}
+	rr, err := dnsRecordToRR(record, record.TTL)
+	if err != nil {
+		return err
+	}
+
+	if err := r.update(ctx, func(msg *dns.Msg) {
+		msg.Remove([]dns.RR{old})
+		msg.Insert([]dns.RR{rr})
+	}); err != nil {
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package dns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// splitTXT splits a text into the character strings of at most 255 bytes a TXT record consists of.
+func splitTXT(text string) []string {
+	parts := make([]string, 0, len(text)/255+1)
+	for len(text) > 255 {
+		parts = append(parts, text[:255])
+		text = text[255:]
+	}
+	return append(parts, text)
+}
+
+func rrToDnsRecord(rr dns.RR) DnsRecord {
+	header := rr.Header()
+	record := DnsRecord{
+		Name: strings.TrimSuffix(header.Name, "."),
+		Type: dns.TypeToString[header.Rrtype],
+		TTL:  header.Ttl,
+	}
+
+	switch rr := rr.(type) {
+	case *dns.A:
+		record.Content = rr.A.String()
+	case *dns.AAAA:
+		record.Content = rr.AAAA.String()
+	case *dns.CNAME:
+		record.Content = strings.TrimSuffix(rr.Target, ".")
+	case *dns.NS:
+		record.Content = strings.TrimSuffix(rr.Ns, ".")
+	case *dns.PTR:
+		record.Content = strings.TrimSuffix(rr.Ptr, ".")
+	case *dns.MX:
+		record.Content = rr.Mx
+		if rr.Mx != "." {
+			record.Content = strings.TrimSuffix(rr.Mx, ".")
+		}
+		record.Priority = rr.Preference
+	case *dns.TXT:
+		record.Content = strings.Join(rr.Txt, "")
+	case *dns.SRV:
+		record.Content = rr.Target
+		if rr.Target != "." {
+			record.Content = strings.TrimSuffix(rr.Target, ".")
+		}
+		record.Priority = rr.Priority
+		record.Weight = rr.Weight
+		record.Port = rr.Port
+	case *dns.CAA:
+		record.Content = rr.Value
+		record.Flags = rr.Flag
+		record.Tag = rr.Tag
+	default:
+		record.Content = strings.TrimPrefix(rr.String(), header.String())
+	}
+
+	return record
+}
+
+func dnsRecordToRR(record DnsRecord, ttl uint32) (dns.RR, error) {
+	rrtype, ok := dns.StringToType[record.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid record type: %s", record.Type)
+	}
+
+	header := dns.RR_Header{
+		Name:   dns.Fqdn(record.Name),
+		Rrtype: rrtype,
+		Class:  dns.ClassINET,
+		Ttl:    ttl,
+	}
+
+	switch record.Type {
+	case "A":
+		ip := net.ParseIP(record.Content).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address")
+		}
+		return &dns.A{Hdr: header, A: ip}, nil
+	case "AAAA":
+		ip := net.ParseIP(record.Content)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv6 address")
+		}
+		return &dns.AAAA{Hdr: header, AAAA: ip}, nil
+	case "CNAME":
+		return &dns.CNAME{Hdr: header, Target: dns.Fqdn(record.Content)}, nil
+	case "NS":
+		return &dns.NS{Hdr: header, Ns: dns.Fqdn(record.Content)}, nil
+	case "PTR":
+		return &dns.PTR{Hdr: header, Ptr: dns.Fqdn(record.Content)}, nil
+	case "MX":
+		return &dns.MX{Hdr: header, Preference: record.Priority, Mx: dns.Fqdn(record.Content)}, nil
+	case "TXT":
+		return &dns.TXT{Hdr: header, Txt: splitTXT(record.Content)}, nil
+	case "SRV":
+		return &dns.SRV{Hdr: header, Priority: record.Priority, Weight: record.Weight, Port: record.Port, Target: dns.Fqdn(record.Content)}, nil
+	case "CAA":
+		return &dns.CAA{Hdr: header, Flag: record.Flags, Tag: record.Tag, Value: record.Content}, nil
+	default:
+		return nil, fmt.Errorf("invalid record type: %s", record.Type)
+	}
+}
+
+// rdataString returns the presentation format of the record data without the header.
+func rdataString(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// rrID derives a record ID from owner, type and data for providers without record IDs.
+// The ID stays the same if only the TTL changes.
+func rrID(rr dns.RR) string {
+	header := rr.Header()
+	sum := sha256.Sum256([]byte(strings.ToLower(header.Name) + " " + dns.TypeToString[header.Rrtype] + " " + rdataString(rr)))
+	return hex.EncodeToString(sum[:5])
+}
+
+// rrToIdentifiedRecord converts the record and sets its derived ID.
+func rrToIdentifiedRecord(rr dns.RR) DnsRecord {
+	record := rrToDnsRecord(rr)
+	record.ID = rrID(rr)
+	return record
+}
@@ -0,0 +1,111 @@
+package dns
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDnsRecordToRR(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  DnsRecord
+		want    string
+		wantErr bool
+	}{
+		{name: "A", record: DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}, want: "www.example.com.\t300\tIN\tA\t192.0.2.1"},
+		{name: "AAAA", record: DnsRecord{Name: "example.com", Type: "AAAA", Content: "2001:db8::1"}, want: "example.com.\t300\tIN\tAAAA\t2001:db8::1"},
+		{name: "CNAME", record: DnsRecord{Name: "www.example.com", Type: "CNAME", Content: "example.com"}, want: "www.example.com.\t300\tIN\tCNAME\texample.com."},
+		{name: "MX", record: DnsRecord{Name: "example.com", Type: "MX", Content: "mail.example.com", Priority: 10}, want: "example.com.\t300\tIN\tMX\t10 mail.example.com."},
+		{name: "TXT", record: DnsRecord{Name: "example.com", Type: "TXT", Content: "v=spf1 -all"}, want: "example.com.\t300\tIN\tTXT\t\"v=spf1 -all\""},
+		{name: "SRV", record: DnsRecord{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com", Priority: 10, Weight: 5, Port: 5060}, want: "_sip._tcp.example.com.\t300\tIN\tSRV\t10 5 5060 sip.example.com."},
+		{name: "CAA", record: DnsRecord{Name: "example.com", Type: "CAA", Content: "letsencrypt.org", Flags: 128, Tag: "issue"}, want: "example.com.\t300\tIN\tCAA\t128 issue \"letsencrypt.org\""},
+		{name: "invalid IPv4 address", record: DnsRecord{Name: "example.com", Type: "A", Content: "2001:db8::1"}, wantErr: true},
+		{name: "invalid type", record: DnsRecord{Name: "example.com", Type: "SOA"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr, err := dnsRecordToRR(tt.record, 300)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dnsRecordToRR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if rr.String() != tt.want {
+				t.Errorf("dnsRecordToRR() = %q, want %q", rr.String(), tt.want)
+			}
+
+			want := tt.record
+			want.TTL = 300
+			if got := rrToDnsRecord(rr); !reflect.DeepEqual(got, want) {
+				t.Errorf("rrToDnsRecord() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSplitTXT(t *testing.T) {
+	long := strings.Repeat("a", 255) + strings.Repeat("b", 255) + "c"
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: []string{""}},
+		{name: "short", text: "v=spf1 -all", want: []string{"v=spf1 -all"}},
+		{name: "exactly 255 bytes", text: long[:255], want: []string{long[:255]}},
+		{name: "long", text: long, want: []string{long[:255], long[255:510], "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitTXT(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTXT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	rr, err := dnsRecordToRR(DnsRecord{Name: "example.com", Type: "TXT", Content: long}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rrToDnsRecord(rr).Content; got != long {
+		t.Errorf("rrToDnsRecord() content has %d bytes, want the %d bytes of the split text", len(got), len(long))
+	}
+}
+
+func TestRRID(t *testing.T) {
+	record := DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1"}
+	rr, err := dnsRecordToRR(record, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := rrID(rr)
+
+	tests := []struct {
+		name   string
+		record DnsRecord
+		ttl    uint32
+		same   bool
+	}{
+		{name: "other TTL", record: record, ttl: 3600, same: true},
+		{name: "other case", record: DnsRecord{Name: "WWW.Example.com", Type: "A", Content: "192.0.2.1"}, ttl: 300, same: true},
+		{name: "other content", record: DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.2"}, ttl: 300},
+		{name: "other name", record: DnsRecord{Name: "api.example.com", Type: "A", Content: "192.0.2.1"}, ttl: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other, err := dnsRecordToRR(tt.record, tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rrID(other) == id; got != tt.same {
+				t.Errorf("rrID() = %s for %s, want same ID as %s: %v", rrID(other), other, id, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Zone is a configured domain together with its repository.
+type Zone struct {
+	Name string
+	Repo DnsRepository
+}
+
+// OpenConfiguredZones returns the repositories of all domains in the dns section of the config.
+func OpenConfiguredZones(provider, token string) ([]Zone, error) {
+	names := ConfiguredZones()
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no domains configured below dns in the config")
+	}
+
+	zones := make([]Zone, 0, len(names))
+	for _, name := range names {
+		repo, err := GetRepoFromViperOrFlag(name, provider, token)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		zones = append(zones, Zone{Name: name, Repo: repo})
+	}
+	return zones, nil
+}
+
+// ZoneRecord is a record together with the zone it was listed from.
+type ZoneRecord struct {
+	Zone      string `json:"zone" yaml:"zone"`
+	DnsRecord `yaml:",inline"`
+}
+
+type ZoneRecordList []ZoneRecord
+
+func (z ZoneRecordList) records() DnsRecordList {
+	records := make(DnsRecordList, len(z))
+	for i, record := range z {
+		records[i] = record.DnsRecord
+	}
+	return records
+}
+
+// tableColumns adds the zone in front of the columns of the records.
+func (z ZoneRecordList) tableColumns(wide bool) []tableColumn[ZoneRecord] {
+	columns := []tableColumn[ZoneRecord]{{header: "ZONE", value: func(r ZoneRecord) string { return r.Zone }}}
+	for _, column := range z.records().tableColumns(wide) {
+		zoneColumn := tableColumn[ZoneRecord]{
+			header:     column.header,
+			rightAlign: column.rightAlign,
+			minWidth:   column.minWidth,
+			maxWidth:   column.maxWidth,
+			value:      func(r ZoneRecord) string { return column.value(r.DnsRecord) },
+		}
+		if column.color != nil {
+			zoneColumn.color = func(r ZoneRecord) string { return column.color(r.DnsRecord) }
+		}
+		columns = append(columns, zoneColumn)
+	}
+	return columns
+}
+
+func (z ZoneRecordList) AsTableString() string {
+	return renderTable(z, z.tableColumns(false))
+}
+
+// RenderZoneRecords writes the records of several zones in the format of the renderer.
+func (r *Renderer) RenderZoneRecords(w io.Writer, records ZoneRecordList) error {
+	return render(w, r, records, records.tableColumns)
+}
+
+// ListZoneRecords lists the records matching the filter in every zone, with at most
+// concurrency zones at the same time. Zones that fail are reported in the error, the
+// records of the other zones are returned anyway.
+func ListZoneRecords(ctx context.Context, zones []Zone, filter RecordFilter, concurrency int) (ZoneRecordList, error) {
+	if err := filter.Compile(); err != nil {
+		return nil, err
+	}
+
+	lists := make([]DnsRecordList, len(zones))
+	errs := make([]error, len(zones))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, zone := range zones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("%s: %w", zone.Name, ctx.Err())
+				return
+			}
+
+			records, err := FilterRecords(ctx, zone.Repo, filter)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", zone.Name, err)
+				return
+			}
+			lists[i] = records
+		}()
+	}
+	wg.Wait()
+
+	records := make(ZoneRecordList, 0)
+	for i, zone := range zones {
+		for _, record := range lists[i] {
+			records = append(records, ZoneRecord{Zone: zone.Name, DnsRecord: record})
+		}
+	}
+	return records, errors.Join(errs...)
+}
+
+// MatchesSearch reports whether the name or content of the record matches the value.
+// An IP address matches the same address in any notation, a CIDR range every address
+// in it and anything else is searched for as substring, ignoring case.
+func (r DnsRecord) MatchesSearch(value string) bool {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		content, err := netip.ParseAddr(r.Content)
+		return err == nil && content.Unmap() == addr.Unmap()
+	}
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		content, err := netip.ParseAddr(r.Content)
+		return err == nil && prefix.Masked().Contains(content.Unmap())
+	}
+
+	value = strings.ToLower(strings.TrimSuffix(value, "."))
+	return strings.Contains(strings.ToLower(r.Name), value) || strings.Contains(strings.ToLower(r.Content), value)
+}
+
+// ContentReplacement is the change of a record whose content is replaced.
+type ContentReplacement struct {
+	Zone   string    `json:"zone"`
+	Before DnsRecord `json:"before"`
+	After  DnsRecord `json:"after"`
+}
+
+type ContentReplacementList []ContentReplacement
+
+func (c ContentReplacementList) AsTableString() string {
+	return renderTable(c, []tableColumn[ContentReplacement]{
+		{header: "ZONE", value: func(r ContentReplacement) string { return r.Zone }},
+		{header: "TYPE", minWidth: 5, value: func(r ContentReplacement) string { return r.Before.Type }},
+		{header: "NAME", value: func(r ContentReplacement) string { return r.Before.Name }},
+		{header: "CONTENT", maxWidth: contentMaxWidth, value: func(r ContentReplacement) string { return r.Before.Content }},
+		{header: "NEW CONTENT", maxWidth: contentMaxWidth, value: func(r ContentReplacement) string { return r.After.Content }},
+	})
+}
+
+// PlanContentReplacement returns the change of every record whose content is old,
+// ignoring case and a trailing dot. The new content has to be valid for the type of
+// each record.
+func PlanContentReplacement(records ZoneRecordList, old, new string) (ContentReplacementList, error) {
+	if old == "" || new == "" {
+		return nil, fmt.Errorf("the old and the new content are required")
+	}
+
+	replacements := make(ContentReplacementList, 0)
+	for _, record := range records {
+		if !strings.EqualFold(strings.TrimSuffix(record.Content, "."), strings.TrimSuffix(old, ".")) {
+			continue
+		}
+
+		after := record.DnsRecord
+		after.Content = new
+		after, err := NormalizeRecord(after)
+		if err != nil {
+			return nil, fmt.Errorf("%s record %s: %w", record.Type, record.Name, err)
+		}
+		replacements = append(replacements, ContentReplacement{Zone: record.Zone, Before: record.DnsRecord, After: after})
+	}
+	return replacements, nil
+}
+
+// ReplaceContent updates the records of the replacements, selected by their ID, in the
+// repositories of their zones.
+func ReplaceContent(ctx context.Context, zones []Zone, replacements ContentReplacementList, concurrency int) RecordResultList {
+	ops := make([]RecordOperation, 0, len(replacements))
+	for _, replacement := range replacements {
+		idx := slices.IndexFunc(zones, func(z Zone) bool { return z.Name == replacement.Zone })
+		ops = append(ops, RecordOperation{
+			Action: ChangeUpdate,
+			Record: replacement.After,
+			Run: func(ctx context.Context) error {
+				if idx < 0 {
+					return fmt.Errorf("zone %s is not configured", replacement.Zone)
+				}
+				return zones[idx].Repo.UpdateRecord(ctx, replacement.After)
+			},
+		})
+	}
+	return RunOperations(ctx, concurrency, ops)
+}
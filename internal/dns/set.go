@@ -0,0 +1,184 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetAction describes what happened to a record in SetRecord and UpdateRecords.
+type SetAction string
+
+const (
+	SetCreated   SetAction = "created"
+	SetUpdated   SetAction = "updated"
+	SetUnchanged SetAction = "unchanged"
+)
+
+type SetResult struct {
+	Action SetAction `json:"action"`
+	Record DnsRecord `json:"record"`
+}
+
+type SetResultList []SetResult
+
+// FindRecords returns the records with the name and type of filter. If the ID or content
+// of filter is set, only records with that ID or content are returned.
+func FindRecords(ctx context.Context, repo DnsRepository, filter DnsRecord) (DnsRecordList, error) {
+	records, err := repo.ListRecords(ctx, filter.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make(DnsRecordList, 0)
+	for _, record := range records {
+		if strings.EqualFold(record.Name, filter.Name) && record.matchesFilter(filter) {
+			matching = append(matching, record)
+		}
+	}
+	return matching, nil
+}
+
+// updateRecords applies the update to every target record. Records that already
+// match the update are not sent to the provider and reported as unchanged.
+func updateRecords(ctx context.Context, repo DnsRepository, targets DnsRecordList, record DnsRecord) (SetResultList, error) {
+	results := make(SetResultList, 0, len(targets))
+	for _, existing := range targets {
+		update := record
+		update.ID = existing.ID
+
+		merged := mergeRecord(existing, update)
+		merged.ID = existing.ID
+		if reflect.DeepEqual(existing, merged) {
+			results = append(results, SetResult{Action: SetUnchanged, Record: existing})
+			continue
+		}
+
+		if err := repo.UpdateRecord(ctx, update); err != nil {
+			return results, fmt.Errorf("failed to update %s record %s (%s): %w", existing.Type, existing.Name, existing.Content, err)
+		}
+		results = append(results, SetResult{Action: SetUpdated, Record: merged})
+	}
+	return results, nil
+}
+
+// UpdateRecords updates the records with the name and type of record. With all every
+// matching record is updated, otherwise the record with the ID of record or the only
+// record with its name and type. Several matching records without an ID are an error.
+func UpdateRecords(ctx context.Context, repo DnsRepository, record DnsRecord, all bool) (SetResultList, error) {
+	matching, err := FindRecords(ctx, repo, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		if len(matching) == 0 {
+			return nil, fmt.Errorf("%w: %s record %s", ErrRecordNotFound, record.Type, record.Name)
+		}
+		return updateRecords(ctx, repo, matching, record)
+	}
+
+	idx, err := selectRecord(matching, record)
+	if err != nil {
+		return nil, err
+	}
+	return updateRecords(ctx, repo, matching[idx:idx+1], record)
+}
+
+// SetRecord creates the record if no record with its name and type exists and updates
+// it otherwise. Among several records the one with the same content is updated, if
+// neither an ID nor all is given.
+func SetRecord(ctx context.Context, repo DnsRepository, record DnsRecord, all bool) (SetResultList, error) {
+	matching, err := FindRecords(ctx, repo, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matching) == 0 {
+		if record.ID != "" {
+			return nil, fmt.Errorf("%w: %s record %s with id %s", ErrRecordNotFound, record.Type, record.Name, record.ID)
+		}
+		record, err := CompleteRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.CreateRecord(ctx, record); err != nil {
+			return nil, err
+		}
+		return SetResultList{{Action: SetCreated, Record: record}}, nil
+	}
+
+	if all {
+		return updateRecords(ctx, repo, matching, record)
+	}
+
+	if record.ID == "" {
+		for _, existing := range matching {
+			if existing.matchesContent(record.Content) {
+				record.ID = existing.ID
+				break
+			}
+		}
+	}
+
+	idx, err := selectRecord(matching, record)
+	if err != nil {
+		return nil, err
+	}
+	return updateRecords(ctx, repo, matching[idx:idx+1], record)
+}
+
+// AddValue adds the record as another value to the set of records with its name and
+// type. Nothing is sent if the value already exists. Without a TTL the new value takes
+// the TTL of the existing values. It returns the values of the set afterwards.
+func AddValue(ctx context.Context, repo DnsRepository, record DnsRecord) (SetAction, DnsRecordList, error) {
+	matching, err := FindRecords(ctx, repo, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, existing := range matching {
+		if sameRecord(existing, record) {
+			return SetUnchanged, matching, nil
+		}
+	}
+	if record.Type == "CNAME" && len(matching) > 0 {
+		return "", nil, fmt.Errorf("%s already has a CNAME record, which can not have several values", record.Name)
+	}
+
+	if record.TTL == 0 && len(matching) > 0 {
+		record.TTL = matching[0].TTL
+	}
+	if err := repo.CreateRecord(ctx, record); err != nil {
+		return "", nil, err
+	}
+	return SetCreated, append(matching, record), nil
+}
+
+// RemoveValue deletes the records with the content of record and keeps the other values
+// with its name and type. A value that does not exist is not an error. It returns the
+// deleted records and the values that are left.
+func RemoveValue(ctx context.Context, repo DnsRepository, record DnsRecord) (DnsRecordList, DnsRecordList, error) {
+	if record.Content == "" {
+		return nil, nil, fmt.Errorf("content is required to remove a value")
+	}
+
+	matching, err := FindRecords(ctx, repo, DnsRecord{Name: record.Name, Type: record.Type})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remaining := make(DnsRecordList, 0, len(matching))
+	for _, existing := range matching {
+		if !existing.matchesContent(record.Content) {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(matching) {
+		return DnsRecordList{}, remaining, nil
+	}
+
+	deleted, err := repo.DeleteRecord(ctx, DnsRecord{Name: record.Name, Type: record.Type, Content: record.Content})
+	return deleted, remaining, err
+}
@@ -0,0 +1,212 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/akatran/internal/utils"
+)
+
+// snapshotVersion is the version of the snapshot file format.
+const snapshotVersion = 1
+
+// LatestSnapshot selects the latest snapshot of a zone instead of its ID.
+const LatestSnapshot = "latest"
+
+// Snapshot is a copy of all records of a zone at one point in time.
+type Snapshot struct {
+	Version   int           `json:"version"`
+	ID        string        `json:"id"`
+	Zone      string        `json:"zone"`
+	CreatedAt time.Time     `json:"created_at"`
+	Records   DnsRecordList `json:"records"`
+}
+
+// SnapshotInfo describes a snapshot without its records.
+type SnapshotInfo struct {
+	ID        string    `json:"id" yaml:"id"`
+	Zone      string    `json:"zone" yaml:"zone"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Records   int       `json:"records" yaml:"records"`
+}
+
+type SnapshotInfoList []SnapshotInfo
+
+func (s SnapshotInfoList) tableColumns(bool) []tableColumn[SnapshotInfo] {
+	return []tableColumn[SnapshotInfo]{
+		{header: "ZONE", value: func(s SnapshotInfo) string { return s.Zone }},
+		{header: "ID", value: func(s SnapshotInfo) string { return s.ID }},
+		{header: "CREATED", value: func(s SnapshotInfo) string { return s.CreatedAt.Local().Format(time.DateTime) }},
+		{header: "RECORDS", rightAlign: true, value: func(s SnapshotInfo) string { return strconv.Itoa(s.Records) }},
+	}
+}
+
+// RenderSnapshots writes the snapshots in the format of the renderer.
+func (r *Renderer) RenderSnapshots(w io.Writer, snapshots SnapshotInfoList) error {
+	return render(w, r, snapshots, snapshots.tableColumns)
+}
+
+// SnapshotStore keeps the snapshots of every zone as JSON files in a directory per zone.
+type SnapshotStore struct {
+	dir string
+}
+
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{dir: dir}
+}
+
+func (s *SnapshotStore) path(zone, id string) string {
+	return filepath.Join(s.dir, zone, id+".json")
+}
+
+// Take lists the records of the zone and saves them as a new snapshot. The ID is the
+// time of the snapshot, so the IDs of a zone sort in the order they were taken.
+func (s *SnapshotStore) Take(ctx context.Context, repo DnsRepository, zone string) (*Snapshot, error) {
+	records, err := repo.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Version:   snapshotVersion,
+		Zone:      zone,
+		CreatedAt: time.Now().UTC(),
+		Records:   records,
+	}
+	if err := os.MkdirAll(filepath.Join(s.dir, zone), 0o700); err != nil {
+		return nil, err
+	}
+
+	snapshot.ID = snapshot.CreatedAt.Format("20060102T150405Z")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.path(zone, snapshot.ID)); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		snapshot.ID = fmt.Sprintf("%s-%d", snapshot.CreatedAt.Format("20060102T150405Z"), i)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.WriteFileAtomic(s.path(zone, snapshot.ID), data, 0o600); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *SnapshotStore) ids(zone string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, zone))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Load reads the snapshot of the zone with the ID or LatestSnapshot.
+func (s *SnapshotStore) Load(zone, id string) (*Snapshot, error) {
+	if id == LatestSnapshot {
+		ids, err := s.ids(zone)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no snapshots of %s found", zone)
+		}
+		id = ids[len(ids)-1]
+	}
+
+	data, err := os.ReadFile(s.path(zone, id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %s of %s not found", id, zone)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s of %s: %w", id, zone, err)
+	}
+	if snapshot.Version > snapshotVersion {
+		return nil, fmt.Errorf("snapshot %s of %s has version %d, which this version of akatran can not read", id, zone, snapshot.Version)
+	}
+	return &snapshot, nil
+}
+
+// List returns the snapshots of the zone, or of every zone if zone is empty, the oldest first.
+func (s *SnapshotStore) List(zone string) (SnapshotInfoList, error) {
+	zones := []string{zone}
+	if zone == "" {
+		entries, err := os.ReadDir(s.dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return SnapshotInfoList{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		zones = zones[:0]
+		for _, entry := range entries {
+			if entry.IsDir() {
+				zones = append(zones, entry.Name())
+			}
+		}
+	}
+
+	infos := make(SnapshotInfoList, 0)
+	for _, zone := range zones {
+		ids, err := s.ids(zone)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			snapshot, err := s.Load(zone, id)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, SnapshotInfo{ID: snapshot.ID, Zone: snapshot.Zone, CreatedAt: snapshot.CreatedAt, Records: len(snapshot.Records)})
+		}
+	}
+	return infos, nil
+}
+
+// NewRestorePlan computes the changes that bring the zone back to the records of the
+// snapshot. Every record of the zone is managed by the snapshot, so records created
+// after the snapshot are deleted. Comments and tags are compared exactly, so the ones
+// added after the snapshot are removed.
+func NewRestorePlan(ctx context.Context, repo DnsRepository, snapshot *Snapshot) (*Plan, error) {
+	records := make([]DnsRecord, 0, len(snapshot.Records))
+	for _, record := range snapshot.Records {
+		record.ID = ""
+		records = append(records, record)
+	}
+
+	return NewPlan(ctx, repo, &ZoneSpec{
+		Zone:      snapshot.Zone,
+		Ownership: OwnershipAll,
+		Records:   records,
+		exact:     true,
+	})
+}
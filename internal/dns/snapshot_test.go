@@ -0,0 +1,72 @@
+package dns
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestNewRestorePlan(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t,
+		DnsRecord{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		DnsRecord{Name: "api.example.com", Type: "A", Content: "192.0.2.2", TTL: 300, Comment: "api", Tags: []string{"env:prod"}},
+	)
+	records, err := repo.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &Snapshot{Zone: "example.com", Records: records}
+
+	// Changes after the snapshot: a comment and tags added, a comment changed and a record created.
+	changes := []DnsRecord{
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1", Comment: "added later", Tags: []string{"team:web"}},
+		{Name: "api.example.com", Type: "A", Content: "192.0.2.2", Comment: "changed"},
+	}
+	for _, change := range changes {
+		if err := repo.UpdateRecord(ctx, change); err != nil {
+			t.Fatalf("UpdateRecord() error = %v", err)
+		}
+	}
+	if err := repo.CreateRecord(ctx, DnsRecord{Name: "new.example.com", Type: "A", Content: "192.0.2.3"}); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+
+	plan, err := NewRestorePlan(ctx, repo, snapshot)
+	if err != nil {
+		t.Fatalf("NewRestorePlan() error = %v", err)
+	}
+	want := []string{
+		"delete A new.example.com 192.0.2.3",
+		"update A api.example.com 192.0.2.2",
+		"update A www.example.com 192.0.2.1",
+	}
+	if got := planSummary(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewRestorePlan() = %q, want %q", got, want)
+	}
+
+	if err := plan.Apply(ctx, repo, 2).Err(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	restored, err := repo.ListRecords(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != len(records) {
+		t.Fatalf("records after restore = %+v, want %+v", restored, records)
+	}
+	for i := range restored {
+		got, want := restored[i], records[i]
+		if got.ID != want.ID || got.Comment != want.Comment || !slices.Equal(got.Tags, want.Tags) {
+			t.Errorf("record %s = %+v, want %+v", want.Name, got, want)
+		}
+	}
+
+	again, err := NewRestorePlan(ctx, repo, snapshot)
+	if err != nil {
+		t.Fatalf("NewRestorePlan() error = %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("NewRestorePlan() after restore = %q, want no changes", planSummary(again))
+	}
+}
@@ -0,0 +1,173 @@
+package dns
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultProviderTimeout = 30 * time.Second
+	DefaultProviderRetries = 3
+
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryTransport retries failed provider requests. Idempotent requests are retried
+// on network errors and 5xx responses, all requests are retried on 429 responses
+// because the provider did not process them. A Retry-After header takes precedence
+// over the jittered exponential backoff. If it asks to wait longer than MaxBackoff,
+// the response is returned instead of blocking the command.
+type RetryTransport struct {
+	Base http.RoundTripper
+
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// Timeout limits every single attempt including reading the response body.
+	Timeout    time.Duration
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewProviderClient returns the http client shared by all HTTP based providers.
+func NewProviderClient(timeout time.Duration, retries int) *http.Client {
+	return &http.Client{
+		Transport: &RetryTransport{
+			Base:       http.DefaultTransport,
+			MaxRetries: retries,
+			Timeout:    timeout,
+			MinBackoff: defaultMinBackoff,
+			MaxBackoff: defaultMaxBackoff,
+		},
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *RetryTransport) maxBackoff() time.Duration {
+	if t.MaxBackoff <= 0 {
+		return defaultMaxBackoff
+	}
+	return t.MaxBackoff
+}
+
+// backoff returns a random duration up to the exponential backoff of the attempt.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	minBackoff, maxBackoff := t.MinBackoff, t.maxBackoff()
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+
+	backoff := maxBackoff
+	if attempt < 32 {
+		backoff = min(minBackoff<<attempt, maxBackoff)
+	}
+	return minBackoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+}
+
+// parseRetryAfter parses the Retry-After header, which is either in seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// cancelBody cancels the timeout context of an attempt once the body was read.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelBody) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	idempotent := isIdempotent(req.Method)
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := req.Context(), context.CancelFunc(func() {})
+		if t.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		}
+
+		attemptReq := req.Clone(ctx)
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		res, err := base.RoundTrip(attemptReq)
+
+		wait := t.backoff(attempt)
+		retry := false
+		switch {
+		case err != nil:
+			retry = idempotent && req.Context().Err() == nil
+		case res.StatusCode == http.StatusTooManyRequests:
+			retry = true
+		case res.StatusCode >= http.StatusInternalServerError:
+			retry = idempotent
+		}
+		// Requests with a body can only be repeated if the body can be recreated.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			retry = false
+		}
+		if retry && res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+				wait = retryAfter
+				retry = retryAfter <= t.maxBackoff()
+			}
+		}
+
+		if !retry || attempt >= t.MaxRetries {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+			return res, nil
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+		cancel()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
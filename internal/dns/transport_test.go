@@ -0,0 +1,102 @@
+package dns
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{name: "empty", value: "", ok: false},
+		{name: "seconds", value: "120", want: 2 * time.Minute, ok: true},
+		{name: "zero", value: "0", want: 0, ok: true},
+		{name: "negative", value: "-5", ok: false},
+		{name: "date", value: "Wed, 01 May 2024 10:15:30 GMT", want: 30 * time.Second, ok: true},
+		{name: "date in the past", value: "Wed, 01 May 2024 10:00:00 GMT", want: 0, ok: true},
+		{name: "invalid", value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		// statuses are answered in order, the last one for all further attempts.
+		statuses   []int
+		retryAfter string
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantStatus: 200, wantCalls: 1},
+		{name: "server error is retried", method: http.MethodGet, statuses: []int{503, 502, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "retries are limited", method: http.MethodGet, statuses: []int{500}, wantStatus: 500, wantCalls: 3},
+		{name: "post is not retried on server error", method: http.MethodPost, statuses: []int{500, 200}, wantStatus: 500, wantCalls: 1},
+		{name: "post is retried on 429", method: http.MethodPost, statuses: []int{429, 200}, wantStatus: 200, wantCalls: 2},
+		{name: "client error is not retried", method: http.MethodGet, statuses: []int{404, 200}, wantStatus: 404, wantCalls: 1},
+		{name: "short retry after is honored", method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "0", wantStatus: 200, wantCalls: 2},
+		{name: "long retry after returns the 429", method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "3600", wantStatus: 429, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := int(calls.Add(1))
+				if body, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(body) != "payload" {
+					t.Errorf("attempt %d got body %q", call, body)
+				}
+				status := tt.statuses[min(call, len(tt.statuses))-1]
+				if status == http.StatusTooManyRequests && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &RetryTransport{
+				MaxRetries: 2,
+				Timeout:    time.Second,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 10 * time.Millisecond,
+			}}
+
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
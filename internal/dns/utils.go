@@ -2,7 +2,6 @@ package dns
 
 import (
 	"fmt"
-	"net/http"
 	"os"
 
 	"github.com/akatranlp/akatran/internal/viper"
@@ -17,6 +16,34 @@ func GetRepoFromViperOrFlag(domain string, provider, token string) (DnsRepositor
 		fmt.Fprintln(os.Stderr, "domain not found in config falling back to flag params")
 	}
 
+	return newRepo(domain, keyStart, provider, token)
+}
+
+// GetRepoFromProfile returns the repository of the domain using the provider settings
+// of a named profile in the profiles section of the config, e.g. to migrate a domain.
+func GetRepoFromProfile(domain string, profile string) (DnsRepository, error) {
+	keyStart := "profiles::" + profile
+
+	provider := viper.GetString(keyStart + "::provider")
+	if provider == "" {
+		return nil, fmt.Errorf("profile not found in config: %s", profile)
+	}
+
+	return newRepo(domain, keyStart, provider, "")
+}
+
+// newRepo creates the repository of the domain with the settings found below keyStart.
+func newRepo(domain string, keyStart string, provider, token string) (DnsRepository, error) {
+	timeout := DefaultProviderTimeout
+	if viper.IsSet(keyStart + "::timeout") {
+		timeout = viper.GetDuration(keyStart + "::timeout")
+	}
+	retries := DefaultProviderRetries
+	if viper.IsSet(keyStart + "::retries") {
+		retries = viper.GetInt(keyStart + "::retries")
+	}
+	client := NewProviderClient(timeout, retries)
+
 	var repo DnsRepository
 	switch provider {
 	case CloudflareProvider:
@@ -27,10 +54,54 @@ func GetRepoFromViperOrFlag(domain string, provider, token string) (DnsRepositor
 		if token == "" {
 			return nil, fmt.Errorf("token not provided")
 		}
-		repo = NewCloudflareRepo(domain, token, http.DefaultClient)
+		cloudflareRepo := NewCloudflareRepo(domain, token, client)
+		if pageSize := viper.GetInt(keyStart + "::page_size"); pageSize != 0 {
+			if err := cloudflareRepo.SetPageSize(pageSize); err != nil {
+				return nil, err
+			}
+		}
+		repo = cloudflareRepo
+	case HetznerProvider:
+		if sub := viper.GetString(keyStart + "::token"); sub != "" {
+			token = sub
+		}
+
+		if token == "" {
+			return nil, fmt.Errorf("token not provided")
+		}
+		repo = NewHetznerRepo(domain, token, client)
+	case RFC2136Provider:
+		server := viper.GetString(keyStart + "::server")
+		if server == "" {
+			return nil, fmt.Errorf("server not provided")
+		}
+
+		keyName := viper.GetString(keyStart + "::key_name")
+		keySecret := viper.GetString(keyStart + "::key_secret")
+		if keyName == "" || keySecret == "" {
+			return nil, fmt.Errorf("tsig key not provided")
+		}
+
+		rfc2136Repo, err := NewRFC2136Repo(domain, server, keyName, keySecret, viper.GetString(keyStart+"::algorithm"))
+		if err != nil {
+			return nil, err
+		}
+		repo = rfc2136Repo
+	case ZoneFileProvider:
+		file := viper.GetString(keyStart + "::file")
+		if file == "" {
+			return nil, fmt.Errorf("zone file not provided")
+		}
+		repo = NewZoneFileRepo(domain, file)
+	case MemoryProvider:
+		memoryRepo, err := NewMemoryRepo(domain, viper.GetString(keyStart+"::file"))
+		if err != nil {
+			return nil, err
+		}
+		repo = memoryRepo
 	default:
 		return nil, fmt.Errorf("provider not supported: %s", provider)
 	}
 
-	return repo, nil
+	return newAuditedRepo(repo, domain, provider, auditLog), nil
 }
@@ -0,0 +1,125 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/akatranlp/akatran/internal/viper"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ZoneLister is implemented by repositories that can list all zones of the account.
+type ZoneLister interface {
+	ListZones(ctx context.Context) ([]string, error)
+}
+
+// isInZone reports whether name is the zone itself or one of its sub domains.
+func isInZone(name, zone string) bool {
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+// longestZoneMatch returns the most specific zone that contains name or an empty string.
+func longestZoneMatch(name string, zones []string) string {
+	match := ""
+	for _, zone := range zones {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		if isInZone(name, zone) && len(zone) > len(match) {
+			match = zone
+		}
+	}
+	return match
+}
+
+// ConfiguredZones returns the sorted domains of the dns section of the config.
+func ConfiguredZones() []string {
+	zones := make([]string, 0)
+	for zone := range viper.GetStringMap("dns") {
+		zones = append(zones, zone)
+	}
+	slices.Sort(zones)
+	return zones
+}
+
+// ResolveZone finds the zone of the record name and returns it together with its repository.
+// An explicitly given zone is used as is. Otherwise the most specific configured domain
+// is used, then the registrable domain according to the Public Suffix List. If the
+// provider can list its zones, a more specific zone of the provider, e.g. a delegated
+// sub zone, takes precedence over the registrable domain.
+func ResolveZone(ctx context.Context, name, zone, provider, token string) (string, DnsRepository, error) {
+	return NewZoneResolver(zone, provider, token)(ctx, name)
+}
+
+// NewZoneResolver returns a ZoneResolver that resolves names like ResolveZone. It creates
+// the repository of every zone once and lists the zones of the provider once per
+// registrable domain, so resolving many names of the same zone is cheap.
+func NewZoneResolver(zone, provider, token string) ZoneResolver {
+	var mu sync.Mutex
+	repos := make(map[string]DnsRepository)
+	providerZones := make(map[string][]string)
+
+	getRepo := func(zone string) (DnsRepository, error) {
+		if repo, ok := repos[zone]; ok {
+			return repo, nil
+		}
+		repo, err := GetRepoFromViperOrFlag(zone, provider, token)
+		if err != nil {
+			return nil, err
+		}
+		repos[zone] = repo
+		return repo, nil
+	}
+
+	return func(ctx context.Context, name string) (string, DnsRepository, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+		if zone != "" {
+			zone := strings.ToLower(strings.TrimSuffix(zone, "."))
+			if !isInZone(name, zone) {
+				return "", nil, fmt.Errorf("record %s is not part of zone %s", name, zone)
+			}
+			repo, err := getRepo(zone)
+			return zone, repo, err
+		}
+
+		if zone := longestZoneMatch(name, ConfiguredZones()); zone != "" {
+			repo, err := getRepo(zone)
+			return zone, repo, err
+		}
+
+		zone, err := publicsuffix.EffectiveTLDPlusOne(name)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to determine zone of %s: %w", name, err)
+		}
+
+		repo, err := getRepo(zone)
+		if err != nil {
+			return "", nil, err
+		}
+
+		zones, ok := providerZones[zone]
+		if !ok {
+			lister, ok := UnwrapRepo(repo).(ZoneLister)
+			if !ok {
+				return zone, repo, nil
+			}
+			if zones, err = lister.ListZones(ctx); err != nil {
+				return "", nil, err
+			}
+			providerZones[zone] = zones
+		}
+
+		match := longestZoneMatch(name, zones)
+		if match == "" || match == zone {
+			return zone, repo, nil
+		}
+
+		repo, err = getRepo(match)
+		return match, repo, err
+	}
+}
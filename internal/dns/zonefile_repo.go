@@ -0,0 +1,463 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/akatran/internal/utils"
+	"github.com/miekg/dns"
+)
+
+const ZoneFileProvider string = "zonefile"
+
+const zoneFileDefaultTTL = 3600
+
+type ZoneFileRepo struct {
+	domain string
+	path   string
+
+	// mu serializes the changes, which each read and rewrite the whole file.
+	mu sync.Mutex
+
+	// OnDelete is called before a record is deleted. Returning an error keeps the
+	// record and reports it as failed, which allows simulating partial failures.
+	OnDelete func(record DnsRecord) error
+}
+
+func NewZoneFileRepo(domain, path string) *ZoneFileRepo {
+	return &ZoneFileRepo{
+		domain: domain,
+		path:   path,
+	}
+}
+
+// zoneFileEntry is one logical entry of a zone file. Entries without a record are
+// comments, blank lines or directives and are written back untouched.
+type zoneFileEntry struct {
+	lines         []string
+	rr            dns.RR
+	implicitOwner bool
+}
+
+// record returns the record of the entry, with the trailing comment of a single line entry as comment.
+func (e *zoneFileEntry) record() DnsRecord {
+	record := rrToIdentifiedRecord(e.rr)
+	if len(e.lines) == 1 {
+		_, comment := splitComment(e.lines[0])
+		record.Comment = strings.TrimSpace(strings.TrimPrefix(comment, ";"))
+	}
+	return record
+}
+
+func formatZoneFileLine(rr dns.RR, comment string) string {
+	if comment == "" {
+		return rr.String()
+	}
+	return rr.String() + " ; " + comment
+}
+
+type zoneFile struct {
+	entries    []*zoneFileEntry
+	defaultTTL uint32
+}
+
+// splitComment splits a line into its data and the trailing comment, ignoring semicolons in quotes.
+func splitComment(line string) (string, string) {
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				return line[:i], line[i:]
+			}
+		}
+	}
+	return line, ""
+}
+
+func parenthesesDepth(line string) int {
+	data, _ := splitComment(line)
+	depth := 0
+	inQuotes := false
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuotes = !inQuotes
+		case '(':
+			if !inQuotes {
+				depth++
+			}
+		case ')':
+			if !inQuotes {
+				depth--
+			}
+		}
+	}
+	return depth
+}
+
+// parseTTL parses a TTL in seconds or in the BIND notation like 1h30m.
+func parseTTL(s string) (uint32, bool) {
+	if ttl, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(ttl), true
+	}
+
+	units := map[byte]uint64{'s': 1, 'm': 60, 'h': 60 * 60, 'd': 24 * 60 * 60, 'w': 7 * 24 * 60 * 60}
+
+	var ttl, num uint64
+	hasNum := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			num = num*10 + uint64(c-'0')
+			hasNum = true
+		case units[c|0x20] != 0 && hasNum:
+			ttl += num * units[c|0x20]
+			num = 0
+			hasNum = false
+		default:
+			return 0, false
+		}
+	}
+	if hasNum || ttl > 1<<32-1 {
+		return 0, false
+	}
+	return uint32(ttl), true
+}
+
+func parseZoneFile(content string, domain string) (*zoneFile, error) {
+	zone := &zoneFile{defaultTTL: zoneFileDefaultTTL}
+
+	origin := dns.Fqdn(domain)
+	lastOwner := origin
+	hasTTL := false
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		entry := &zoneFileEntry{lines: []string{lines[i]}}
+		depth := parenthesesDepth(lines[i])
+		for depth > 0 && i+1 < len(lines) {
+			i++
+			entry.lines = append(entry.lines, lines[i])
+			depth += parenthesesDepth(lines[i])
+		}
+		zone.entries = append(zone.entries, entry)
+
+		data, _ := splitComment(entry.lines[0])
+		fields := strings.Fields(data)
+		if len(fields) == 0 && len(entry.lines) == 1 {
+			continue
+		}
+
+		switch {
+		case len(fields) > 0 && strings.EqualFold(fields[0], "$ORIGIN"):
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: $ORIGIN without a domain", i+1)
+			}
+			if dns.IsFqdn(fields[1]) {
+				origin = fields[1]
+			} else {
+				origin = fields[1] + "." + origin
+			}
+			continue
+		case len(fields) > 0 && strings.EqualFold(fields[0], "$TTL"):
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: $TTL without a value", i+1)
+			}
+			ttl, ok := parseTTL(fields[1])
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid $TTL: %s", i+1, fields[1])
+			}
+			zone.defaultTTL = ttl
+			hasTTL = true
+			continue
+		case len(fields) > 0 && strings.HasPrefix(fields[0], "$"):
+			return nil, fmt.Errorf("line %d: unsupported directive: %s", i+1, fields[0])
+		}
+
+		text := strings.Join(entry.lines, "\n")
+		if text[0] == ' ' || text[0] == '\t' {
+			entry.implicitOwner = true
+			text = lastOwner + text
+		}
+		if hasTTL {
+			text = fmt.Sprintf("$TTL %d\n%s", zone.defaultTTL, text)
+		}
+
+		parser := dns.NewZoneParser(strings.NewReader(text), origin, "")
+		rr, ok := parser.Next()
+		if err := parser.Err(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if !ok {
+			continue
+		}
+
+		entry.rr = rr
+		lastOwner = rr.Header().Name
+	}
+
+	return zone, nil
+}
+
+func (z *zoneFile) String() string {
+	var builder strings.Builder
+
+	lastOwner := ""
+	for _, entry := range z.entries {
+		lines := entry.lines
+		if entry.rr != nil {
+			owner := entry.rr.Header().Name
+			// The owner was inherited from the previous record, which might have been removed or changed.
+			if entry.implicitOwner && owner != lastOwner {
+				lines = slices.Clone(lines)
+				lines[0] = owner + lines[0]
+			}
+			lastOwner = owner
+		}
+		for _, line := range lines {
+			fmt.Fprintln(&builder, line)
+		}
+	}
+
+	return builder.String()
+}
+
+func (z *zoneFile) records(name string, type_ string) []*zoneFileEntry {
+	entries := make([]*zoneFileEntry, 0)
+	for _, entry := range z.entries {
+		if entry.rr == nil {
+			continue
+		}
+		header := entry.rr.Header()
+		if name != "" && !strings.EqualFold(header.Name, dns.Fqdn(name)) {
+			continue
+		}
+		if type_ != "" && dns.TypeToString[header.Rrtype] != type_ {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (z *zoneFile) replace(entry *zoneFileEntry, rr dns.RR, comment string) {
+	entry.lines = []string{formatZoneFileLine(rr, comment)}
+	entry.rr = rr
+	entry.implicitOwner = false
+}
+
+func (z *zoneFile) remove(entries []*zoneFileEntry) {
+	z.entries = slices.DeleteFunc(z.entries, func(entry *zoneFileEntry) bool {
+		return slices.Contains(entries, entry)
+	})
+}
+
+func (z *zoneFile) append(rr dns.RR, comment string) {
+	z.entries = append(z.entries, &zoneFileEntry{
+		lines: []string{formatZoneFileLine(rr, comment)},
+		rr:    rr,
+	})
+}
+
+const soaSerialPattern = `(^|[\s(])%d($|[\s)])`
+
+// bumpSerial increments the SOA serial in place, so the formatting of the SOA entry is kept.
+// Date based serials (YYYYMMDDnn) are moved to the current date when they are behind.
+func (z *zoneFile) bumpSerial(now time.Time) error {
+	for _, entry := range z.entries {
+		soa, ok := entry.rr.(*dns.SOA)
+		if !ok {
+			continue
+		}
+
+		serial := soa.Serial + 1
+		if soa.Serial >= 1970010100 && soa.Serial <= 2999123199 {
+			today, _ := strconv.ParseUint(now.Format("20060102")+"00", 10, 32)
+			serial = max(serial, uint32(today))
+		}
+
+		matcher := regexp.MustCompile(fmt.Sprintf(soaSerialPattern, soa.Serial))
+		seenSOA := false
+		for i, line := range entry.lines {
+			data, comment := splitComment(line)
+			if !seenSOA {
+				idx := strings.Index(strings.ToUpper(data), "SOA")
+				if idx < 0 {
+					continue
+				}
+				seenSOA = true
+				prefix, rest := data[:idx+3], data[idx+3:]
+				if loc := matcher.FindStringSubmatchIndex(rest); loc != nil {
+					entry.lines[i] = prefix + rest[:loc[3]] + strconv.FormatUint(uint64(serial), 10) + rest[loc[4]:] + comment
+					soa.Serial = serial
+					return nil
+				}
+				continue
+			}
+			if loc := matcher.FindStringSubmatchIndex(data); loc != nil {
+				entry.lines[i] = data[:loc[3]] + strconv.FormatUint(uint64(serial), 10) + data[loc[4]:] + comment
+				soa.Serial = serial
+				return nil
+			}
+		}
+		return fmt.Errorf("failed to locate soa serial")
+	}
+	return fmt.Errorf("zone file has no soa record")
+}
+
+func (r *ZoneFileRepo) load() (*zoneFile, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseZoneFile(string(data), r.domain)
+}
+
+// save bumps the SOA serial and replaces the zone file atomically.
+func (r *ZoneFileRepo) save(zone *zoneFile) error {
+	if err := zone.bumpSerial(time.Now()); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(r.path, []byte(zone.String()), info.Mode())
+}
+
+func (r *ZoneFileRepo) ListRecords(ctx context.Context, types ...string) (DnsRecordList, error) {
+	if err := ValidateRecordTypes(types...); err != nil {
+		return nil, err
+	}
+
+	zone, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(types) == 0 {
+		types = SupportedRecordTypes
+	}
+
+	records := make([]DnsRecord, 0)
+	for _, entry := range zone.records("", "") {
+		record := entry.record()
+		if !slices.Contains(types, record.Type) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return sortDnsRecords(records), nil
+}
+
+func (r *ZoneFileRepo) CreateRecord(ctx context.Context, record DnsRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	if record.TTL == 0 {
+		record.TTL = zone.defaultTTL
+	}
+
+	rr, err := dnsRecordToRR(record, record.TTL)
+	if err != nil {
+		return err
+	}
+	zone.append(rr, record.Comment)
+
+	return r.save(zone)
+}
+
+func (r *ZoneFileRepo) DeleteRecord(ctx context.Context, record DnsRecord) (DnsRecordList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := utils.Filter(zone.records(record.Name, record.Type), func(entry *zoneFileEntry) bool {
+		return entry.record().matchesFilter(record)
+	})
+	if len(entries) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	removed := make([]*zoneFileEntry, 0, len(entries))
+	deleted := make([]DnsRecord, 0, len(entries))
+	errs := make(deleteErrList, 0)
+	for _, entry := range entries {
+		if r.OnDelete != nil {
+			if err := r.OnDelete(entry.record()); err != nil {
+				errs = append(errs, deleteErr{
+					record: entry.record(),
+					err:    err,
+				})
+				continue
+			}
+		}
+		removed = append(removed, entry)
+		deleted = append(deleted, entry.record())
+	}
+	zone.remove(removed)
+
+	if err := r.save(zone); err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return sortDnsRecords(deleted), errs
+	}
+	return sortDnsRecords(deleted), nil
+}
+
+func (r *ZoneFileRepo) UpdateRecord(ctx context.Context, record DnsRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	entries := zone.records(record.Name, record.Type)
+	idx, err := selectRecord(utils.Map(entries, (*zoneFileEntry).record), record)
+	if err != nil {
+		return err
+	}
+
+	record = mergeRecord(entries[idx].record(), record)
+	if record.TTL == 0 {
+		record.TTL = zone.defaultTTL
+	}
+	rr, err := dnsRecordToRR(record, record.TTL)
+	if err != nil {
+		return err
+	}
+	zone.replace(entries[idx], rr, record.Comment)
+
+	return r.save(zone)
+}
@@ -0,0 +1,222 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testZoneFile = `$ORIGIN example.com.
+$TTL 3600
+; the zone of example.com
+@	IN	SOA	ns1.example.com. hostmaster.example.com. (
+		2024050101 ; serial
+		7200       ; refresh
+		3600       ; retry
+		1209600    ; expire
+		3600 )     ; minimum
+@	IN	NS	ns1.example.com.
+	IN	NS	ns2.example.com.
+
+www	300	IN	A	192.0.2.1 ; web server
+	IN	AAAA	2001:db8::1
+mail	IN	MX	10 mx.example.com.
+_sip._tcp	IN	SRV	10 5 5060 sip.example.com.
+@	IN	TXT	"v=spf1 -all"
+`
+
+func TestParseZoneFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "full zone", content: testZoneFile},
+		{name: "records only", content: "www.example.com. 300 IN A 192.0.2.1\n"},
+		{name: "comments and blank lines", content: "; comment\n\n   \nwww 300 IN A 192.0.2.1 ; trailing\n"},
+		{name: "relative origin", content: "$ORIGIN lab\napp 300 IN CNAME www.example.com.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, err := parseZoneFile(tt.content, "example.com")
+			if err != nil {
+				t.Fatalf("parseZoneFile() error = %v", err)
+			}
+			if got := zone.String(); got != tt.content {
+				t.Errorf("String() =\n%s\nwant\n%s", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestParseZoneFileRecords(t *testing.T) {
+	zone, err := parseZoneFile(testZoneFile, "example.com")
+	if err != nil {
+		t.Fatalf("parseZoneFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name, type_ string
+		want        []string
+	}{
+		{name: "example.com", type_: "NS", want: []string{"ns1.example.com", "ns2.example.com"}},
+		{name: "www.example.com", type_: "A", want: []string{"192.0.2.1"}},
+		{name: "www.example.com", type_: "AAAA", want: []string{"2001:db8::1"}},
+		{name: "mail.example.com", type_: "MX", want: []string{"mx.example.com"}},
+		{name: "example.com", type_: "TXT", want: []string{"v=spf1 -all"}},
+		{name: "missing.example.com", type_: "A", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.type_, func(t *testing.T) {
+			var got []string
+			for _, entry := range zone.records(tt.name, tt.type_) {
+				got = append(got, strings.TrimSuffix(entry.record().Content, "."))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("records(%s, %s) = %v, want %v", tt.name, tt.type_, got, tt.want)
+			}
+		})
+	}
+
+	www := zone.records("www.example.com", "A")[0].record()
+	if www.TTL != 300 || www.Comment != "web server" {
+		t.Errorf("www record = %+v, want ttl 300 and comment %q", www, "web server")
+	}
+}
+
+func TestParseZoneFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "origin without domain", content: "$ORIGIN\n"},
+		{name: "ttl without value", content: "$TTL\n"},
+		{name: "invalid ttl", content: "$TTL forever\n"},
+		{name: "unsupported directive", content: "$INCLUDE other.zone\n"},
+		{name: "invalid record", content: "www IN A not-an-address\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseZoneFile(tt.content, "example.com"); err == nil {
+				t.Errorf("parseZoneFile(%q) error = nil, want an error", tt.content)
+			}
+		})
+	}
+}
+
+func TestBumpSerial(t *testing.T) {
+	now := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "counter",
+			content: "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 41 7200 3600 1209600 3600\n",
+			want:    "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 42 7200 3600 1209600 3600\n",
+		},
+		{
+			name:    "date of today",
+			content: "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 2024050307 7200 3600 1209600 3600\n",
+			want:    "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 2024050308 7200 3600 1209600 3600\n",
+		},
+		{
+			name:    "date behind",
+			content: "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 2024050101 7200 3600 1209600 3600\n",
+			want:    "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. 2024050300 7200 3600 1209600 3600\n",
+		},
+		{
+			name:    "multi line",
+			content: "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. (\n  7 ; serial\n  7200 3600 1209600 3600 )\n",
+			want:    "@ 3600 IN SOA ns1.example.com. hostmaster.example.com. (\n  8 ; serial\n  7200 3600 1209600 3600 )\n",
+		},
+		{
+			name:    "no soa",
+			content: "www 300 IN A 192.0.2.1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, err := parseZoneFile(tt.content, "example.com")
+			if err != nil {
+				t.Fatalf("parseZoneFile() error = %v", err)
+			}
+			err = zone.bumpSerial(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bumpSerial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && zone.String() != tt.want {
+				t.Errorf("String() =\n%s\nwant\n%s", zone.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestZoneFileRepoKeepsFormatting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.com.zone")
+	if err := os.WriteFile(path, []byte(testZoneFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := NewZoneFileRepo("example.com", path)
+	if err := repo.UpdateRecord(ctx, DnsRecord{Name: "mail.example.com", Type: "MX", Content: "mx2.example.com"}); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if err := repo.CreateRecord(ctx, DnsRecord{Name: "api.example.com", Type: "A", Content: "192.0.2.2", TTL: 300}); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+	if _, err := repo.DeleteRecord(ctx, DnsRecord{Name: "www.example.com", Type: "AAAA"}); err != nil {
+		t.Fatalf("DeleteRecord() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"; the zone of example.com\n",
+		"\t\t7200       ; refresh\n",
+		"www\t300\tIN\tA\t192.0.2.1 ; web server\n",
+		"\tIN\tNS\tns2.example.com.\n",
+		"mail.example.com.\t3600\tIN\tMX\t10 mx2.example.com.\n",
+		"api.example.com.\t300\tIN\tA\t192.0.2.2\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("zone file does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "2001:db8::1") {
+		t.Errorf("zone file still contains the deleted record:\n%s", got)
+	}
+	if strings.Contains(got, "2024050101 ; serial") {
+		t.Errorf("serial was not bumped:\n%s", got)
+	}
+
+	records, err := repo.ListRecords(ctx)
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	reparsed, err := parseZoneFile(got, "example.com")
+	if err != nil {
+		t.Fatalf("parseZoneFile() error = %v", err)
+	}
+	if reparsed.String() != got {
+		t.Errorf("the written zone file does not round-trip")
+	}
+	if len(records) != 7 {
+		t.Errorf("ListRecords() = %d records, want 7: %v", len(records), records)
+	}
+}
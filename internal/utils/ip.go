@@ -2,67 +2,94 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os/exec"
 	"strings"
+	"time"
 )
 
-func fetchIPv4() string {
-	res, err := http.Get("https://checkip.amazonaws.com")
+// ipLookupTimeout limits how long the public address is looked up, so a stalled
+// endpoint can not block a caller like the ddns daemon forever.
+const ipLookupTimeout = 10 * time.Second
+
+var ipClient = &http.Client{Timeout: ipLookupTimeout}
+
+func fetchIPv4(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://checkip.amazonaws.com", nil)
 	if err != nil {
-		return ""
+		return "", err
+	}
+	res, err := ipClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the public IPv4 address: %w", err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to look up the public IPv4 address: %s", res.Status)
+	}
+
 	var ip strings.Builder
-	if _, err := io.Copy(&ip, res.Body); err != nil {
-		return ""
+	if _, err := io.Copy(&ip, io.LimitReader(res.Body, 1024)); err != nil {
+		return "", fmt.Errorf("failed to look up the public IPv4 address: %w", err)
 	}
 
-	return strings.TrimSpace(ip.String())
+	return strings.TrimSpace(ip.String()), nil
 }
 
-func fetchIPv6() string {
+func fetchIPv6(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ipLookupTimeout)
+	defer cancel()
+
 	cmdStr := `ip -6 addr show scope global | grep -oP '(?<=inet6\s)[\da-f:]+'`
 	// cmdStr := `ip -6 addr show scope link | grep -oP '(?<=inet6\s)[\da-f:]+'`
-	cmd := exec.Command("sh", "-c", cmdStr)
+	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
 	var buf bytes.Buffer
 	cmd.Stdout = &buf
 	if err := cmd.Run(); err != nil {
-		return err.Error()
+		return "", fmt.Errorf("failed to look up the global IPv6 address: %w", err)
 	}
 
-	return strings.TrimSpace(buf.String())
+	// The first of several global addresses is used.
+	addresses := strings.Fields(buf.String())
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("this machine has no global IPv6 address")
+	}
+	return addresses[0], nil
 }
 
-func GetIPv4Address(ip string) net.IP {
+// GetIPv4Address parses ip or, if it is empty, looks up the public IPv4 address of this machine.
+func GetIPv4Address(ctx context.Context, ip string) (net.IP, error) {
 	if ip == "" {
-		ip = fetchIPv4()
+		var err error
+		if ip, err = fetchIPv4(ctx); err != nil {
+			return nil, err
+		}
 	}
 	addr := net.ParseIP(ip)
 
-	if addr == nil {
-		return nil
+	if addr == nil || addr.To4() == nil {
+		return nil, fmt.Errorf("invalid IPv4 address: %q", ip)
 	}
-	if addr.To4() == nil {
-		return nil
-	}
-	return addr
+	return addr, nil
 }
 
-func GetIPv6Address(ip string) net.IP {
+// GetIPv6Address parses ip or, if it is empty, looks up the global IPv6 address of this machine.
+func GetIPv6Address(ctx context.Context, ip string) (net.IP, error) {
 	if ip == "" {
-		ip = fetchIPv6()
+		var err error
+		if ip, err = fetchIPv6(ctx); err != nil {
+			return nil, err
+		}
 	}
 	addr := net.ParseIP(ip)
 
-	if addr == nil {
-		return nil
-	}
-	if addr.To16() == nil {
-		return nil
+	if addr == nil || addr.To16() == nil {
+		return nil, fmt.Errorf("invalid IPv6 address: %q", ip)
 	}
-	return addr
+	return addr, nil
 }